@@ -0,0 +1,38 @@
+package xim
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WordBoundary - describes characters which split words for tokenizing.
+// A space(U+0020) always splits words.
+type WordBoundary struct {
+	Whitespace  bool   // splits words at any Unicode white space such as tabs, newlines and ideographic spaces(U+3000)
+	Punctuation bool   // splits words at Unicode punctuation such as hyphens, commas and '、'
+	Symbols     bool   // splits words at Unicode symbols such as '+', '$' and '★'
+	Separators  string // additional characters to split words
+}
+
+func isDefaultSeparator(r rune) bool {
+	return r == ' '
+}
+
+func (wb *WordBoundary) isSeparator(r rune) bool {
+	switch {
+	case isDefaultSeparator(r):
+		return true
+	case wb.Whitespace && unicode.IsSpace(r):
+		return true
+	case wb.Punctuation && unicode.IsPunct(r):
+		return true
+	case wb.Symbols && unicode.IsSymbol(r):
+		return true
+	}
+	return strings.ContainsRune(wb.Separators, r)
+}
+
+// Words - splits s into words with the boundary.
+func (wb *WordBoundary) Words(s string) []string {
+	return strings.FieldsFunc(s, wb.isSeparator)
+}
@@ -0,0 +1,66 @@
+package xim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWordBoundaryWords(t *testing.T) {
+	cases := []struct {
+		name     string
+		wb       *WordBoundary
+		s        string
+		expected []string
+	}{
+		{"zero value", &WordBoundary{}, "a\tb c　d", []string{"a\tb", "c　d"}},
+		{"whitespace", &WordBoundary{Whitespace: true}, "a\tb\nc　d  e", []string{"a", "b", "c", "d", "e"}},
+		{"punctuation", &WordBoundary{Punctuation: true}, "a,b e-f 東京、大阪", []string{"a", "b", "e", "f", "東京", "大阪"}},
+		{"symbols", &WordBoundary{Symbols: true}, "a+b $5 ★c", []string{"a", "b", "5", "c"}},
+		{"separators", &WordBoundary{Separators: "/|"}, "a/b|c-d", []string{"a", "b", "c-d"}},
+	}
+
+	for _, c := range cases {
+		c := c // escape: Using the variable on range scope `c` in loop literal
+		t.Run(c.name, func(t *testing.T) {
+			if actual := c.wb.Words(c.s); !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("unexpected, actual: `%q`, expected: `%q`", actual, c.expected)
+			}
+		})
+	}
+}
+
+func TestWordBoundaryIndexAndFilter(t *testing.T) {
+	conf := &Config{WordBoundary: &WordBoundary{Whitespace: true, Punctuation: true}}
+
+	idx := NewIndexes(conf)
+	idx.AddPrefixes("label1", "hello,world　東京-大阪")
+	idx.AddBigrams("label2", "ab,cd")
+
+	assertBuiltIndex(t, idx.MustBuild(), map[string]bool{
+		"label1 h":     true,
+		"label1 he":    true,
+		"label1 hel":   true,
+		"label1 hell":  true,
+		"label1 hello": true,
+		"label1 w":     true,
+		"label1 wo":    true,
+		"label1 wor":   true,
+		"label1 worl":  true,
+		"label1 world": true,
+		"label1 東":     true,
+		"label1 東京":    true,
+		"label1 大":     true,
+		"label1 大阪":    true,
+		"label2 ab":    true,
+		"label2 cd":    true,
+	})
+
+	filter := NewFilters(conf)
+	filter.AddPrefix("label1", "world")
+	filter.AddBigrams("label2", "cd")
+
+	assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+		"label1 world": true,
+		"label2 cd":    true,
+	})
+}
@@ -0,0 +1,559 @@
+package xim
+
+// doubleMetaphoneMaxLength - maximum length of Double Metaphone codes.
+const doubleMetaphoneMaxLength = 4
+
+// DoubleMetaphone - returns the primary and alternate Double Metaphone codes of word.
+// The alternate code is for other pronunciations such as Slavic, Germanic, Spanish and Italian ones,
+// and it's the same as the primary code if word has no other pronunciation.
+// '0' in the codes stands for "th".
+// e.g. "Smith" is "SM0" and "XMT", and "Schmidt" is "XMT" and "SMT".
+func DoubleMetaphone(word string) (primary, alternate string) {
+	w := phoneticLetters(word)
+	if len(w) == 0 {
+		return "", ""
+	}
+
+	m := &doubleMetaphone{w: w}
+	m.slavoGermanic = m.has('W') || m.has('K') || m.containsAny("CZ") || m.containsAny("WITZ")
+	return m.encode()
+}
+
+// doubleMetaphone - state of encoding a word with Double Metaphone.
+type doubleMetaphone struct {
+	w             []byte
+	slavoGermanic bool
+	primary       []byte
+	alternate     []byte
+}
+
+func (m *doubleMetaphone) has(c byte) bool {
+	for _, x := range m.w {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny - reports whether w contains s anywhere.
+func (m *doubleMetaphone) containsAny(s string) bool {
+	for i := 0; i+len(s) <= len(m.w); i++ {
+		if string(m.w[i:i+len(s)]) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// at - returns the letter at i, or 0 out of w.
+func (m *doubleMetaphone) at(i int) byte {
+	if i < 0 || i >= len(m.w) {
+		return 0
+	}
+	return m.w[i]
+}
+
+// contains - reports whether the substring of length letters from start is any of ss.
+func (m *doubleMetaphone) contains(start, length int, ss ...string) bool {
+	if start < 0 || start+length > len(m.w) {
+		return false
+	}
+	sub := string(m.w[start : start+length])
+	for _, s := range ss {
+		if sub == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *doubleMetaphone) isVowel(i int) bool {
+	switch m.at(i) {
+	case 'A', 'E', 'I', 'O', 'U', 'Y':
+		return true
+	}
+	return false
+}
+
+func (m *doubleMetaphone) add(primary, alternate string) {
+	m.primary = append(m.primary, primary...)
+	m.alternate = append(m.alternate, alternate...)
+}
+
+func (m *doubleMetaphone) addBoth(s string) {
+	m.add(s, s)
+}
+
+// skip - returns the next index skipping the letter at i+1 if it's any of cs.
+func (m *doubleMetaphone) skip(i int, cs ...string) int {
+	if m.contains(i+1, 1, cs...) {
+		return i + 2
+	}
+	return i + 1
+}
+
+func (m *doubleMetaphone) encode() (primary, alternate string) {
+	i := 0
+	if m.contains(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		// silent first letter
+		i = 1
+	}
+
+	for i < len(m.w) {
+		switch m.w[i] {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			// vowels are encoded only at the start
+			if i == 0 {
+				m.addBoth("A")
+			}
+			i++
+		case 'B':
+			m.addBoth("P")
+			i = m.skip(i, "B")
+		case 'C':
+			i = m.encodeC(i)
+		case 'D':
+			i = m.encodeD(i)
+		case 'F':
+			m.addBoth("F")
+			i = m.skip(i, "F")
+		case 'G':
+			i = m.encodeG(i)
+		case 'H':
+			if (i == 0 || m.isVowel(i-1)) && m.isVowel(i+1) {
+				m.addBoth("H")
+				i += 2
+			} else {
+				i++
+			}
+		case 'J':
+			i = m.encodeJ(i)
+		case 'K':
+			m.addBoth("K")
+			i = m.skip(i, "K")
+		case 'L':
+			i = m.encodeL(i)
+		case 'M':
+			m.addBoth("M")
+			if m.at(i+1) == 'M' || m.contains(i-1, 3, "UMB") && (i+1 == len(m.w)-1 || m.contains(i+2, 2, "ER")) {
+				i += 2
+			} else {
+				i++
+			}
+		case 'N':
+			m.addBoth("N")
+			i = m.skip(i, "N")
+		case 'P':
+			if m.at(i+1) == 'H' {
+				m.addBoth("F")
+				i += 2
+			} else {
+				m.addBoth("P")
+				i = m.skip(i, "P", "B")
+			}
+		case 'Q':
+			m.addBoth("K")
+			i = m.skip(i, "Q")
+		case 'R':
+			if i == len(m.w)-1 && !m.slavoGermanic && m.contains(i-2, 2, "IE") && !m.contains(i-4, 2, "ME", "MA") {
+				// silent in French endings. e.g. "Rogier"
+				m.add("", "R")
+			} else {
+				m.addBoth("R")
+			}
+			i = m.skip(i, "R")
+		case 'S':
+			i = m.encodeS(i)
+		case 'T':
+			i = m.encodeT(i)
+		case 'V':
+			m.addBoth("F")
+			i = m.skip(i, "V")
+		case 'W':
+			i = m.encodeW(i)
+		case 'X':
+			i = m.encodeX(i)
+		case 'Z':
+			i = m.encodeZ(i)
+		default:
+			i++
+		}
+	}
+
+	return truncateCode(m.primary), truncateCode(m.alternate)
+}
+
+func truncateCode(code []byte) string {
+	if len(code) > doubleMetaphoneMaxLength {
+		code = code[:doubleMetaphoneMaxLength]
+	}
+	return string(code)
+}
+
+func (m *doubleMetaphone) encodeC(i int) int {
+	switch {
+	case m.isGermanicCH(i):
+		// e.g. "Bacher", "Macher"
+		m.addBoth("K")
+		return i + 2
+	case i == 0 && m.contains(i, 6, "CAESAR"):
+		m.addBoth("S")
+		return i + 2
+	case m.contains(i, 2, "CH"):
+		return m.encodeCH(i)
+	case m.contains(i, 2, "CZ") && !m.contains(i-2, 4, "WICZ"):
+		// e.g. "Czerny"
+		m.add("S", "X")
+		return i + 2
+	case m.contains(i+1, 3, "CIA"):
+		// e.g. "focaccia"
+		m.addBoth("X")
+		return i + 3
+	case m.contains(i, 2, "CC") && !(i == 1 && m.at(0) == 'M'):
+		// not "McClellan"
+		if m.contains(i+2, 1, "I", "E", "H") && !m.contains(i+2, 2, "HU") {
+			if i == 1 && m.at(0) == 'A' || m.contains(i-1, 5, "UCCEE", "UCCES") {
+				// e.g. "accident", "success"
+				m.addBoth("KS")
+			} else {
+				// e.g. "bacci", "bertucci"
+				m.addBoth("X")
+			}
+			return i + 3
+		}
+		m.addBoth("K")
+		return i + 2
+	case m.contains(i, 2, "CK", "CG", "CQ"):
+		m.addBoth("K")
+		return i + 2
+	case m.contains(i, 2, "CI", "CE", "CY"):
+		if m.contains(i, 3, "CIO", "CIE", "CIA") {
+			// Italian
+			m.add("S", "X")
+		} else {
+			m.addBoth("S")
+		}
+		return i + 2
+	}
+
+	m.addBoth("K")
+	if m.contains(i+1, 1, "C", "K", "Q") && !m.contains(i+1, 2, "CE", "CI") {
+		return i + 2
+	}
+	return i + 1
+}
+
+// isGermanicCH - reports whether "CH" at i is pronounced as "K" after a vowel and a consonant.
+func (m *doubleMetaphone) isGermanicCH(i int) bool {
+	if m.contains(i, 4, "CHIA") {
+		return true
+	}
+	if i <= 1 || m.isVowel(i-2) || !m.contains(i-1, 3, "ACH") {
+		return false
+	}
+	c := m.at(i + 2)
+	return c != 'I' && c != 'E' || m.contains(i-2, 6, "BACHER", "MACHER")
+}
+
+func (m *doubleMetaphone) encodeCH(i int) int {
+	switch {
+	case i > 0 && m.contains(i, 4, "CHAE"):
+		// e.g. "Michael"
+		m.add("K", "X")
+	case i == 0 && (m.contains(i+1, 5, "HARAC", "HARIS") || m.contains(i+1, 3, "HOR", "HYM", "HIA", "HEM")) &&
+		!m.contains(0, 5, "CHORE"):
+		// Greek roots. e.g. "chemistry", "chorus"
+		m.addBoth("K")
+	case m.contains(0, 3, "SCH") ||
+		m.contains(i-2, 6, "ORCHES", "ARCHIT", "ORCHID") ||
+		m.contains(i+2, 1, "T", "S") ||
+		(i == 0 || m.contains(i-1, 1, "A", "O", "U", "E")) &&
+			(m.contains(i+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W") || i+1 == len(m.w)-1):
+		// Germanic and Greek. e.g. "orchestra", "architect", "Christ"
+		m.addBoth("K")
+	case i > 0:
+		if m.contains(0, 2, "MC") {
+			// e.g. "McHugh"
+			m.addBoth("K")
+		} else {
+			m.add("X", "K")
+		}
+	default:
+		m.addBoth("X")
+	}
+	return i + 2
+}
+
+func (m *doubleMetaphone) encodeD(i int) int {
+	switch {
+	case m.contains(i, 2, "DG"):
+		if m.contains(i+2, 1, "I", "E", "Y") {
+			// e.g. "edge"
+			m.addBoth("J")
+			return i + 3
+		}
+		// e.g. "Edgar"
+		m.addBoth("TK")
+		return i + 2
+	case m.contains(i, 2, "DT", "DD"):
+		m.addBoth("T")
+		return i + 2
+	}
+	m.addBoth("T")
+	return i + 1
+}
+
+func (m *doubleMetaphone) encodeG(i int) int {
+	switch {
+	case m.at(i+1) == 'H':
+		return m.encodeGH(i)
+	case m.at(i+1) == 'N':
+		switch {
+		case i == 1 && m.isVowel(0) && !m.slavoGermanic:
+			m.add("KN", "N")
+		case !m.contains(i+2, 2, "EY") && m.at(i+1) != 'Y' && !m.slavoGermanic:
+			// e.g. "Agnes"
+			m.add("N", "KN")
+		default:
+			m.addBoth("KN")
+		}
+		return i + 2
+	case m.contains(i+1, 2, "LI") && !m.slavoGermanic:
+		// e.g. "tagliaro"
+		m.add("KL", "L")
+		return i + 2
+	case i == 0 && (m.at(i+1) == 'Y' ||
+		m.contains(i+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		m.add("K", "J")
+		return i + 2
+	case (m.contains(i+1, 2, "ER") || m.at(i+1) == 'Y') &&
+		!m.contains(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!m.contains(i-1, 1, "E", "I") && !m.contains(i-1, 3, "RGY", "OGY"):
+		m.add("K", "J")
+		return i + 2
+	case m.contains(i+1, 1, "E", "I", "Y") || m.contains(i-1, 4, "AGGI", "OGGI"):
+		switch {
+		case m.contains(0, 3, "SCH") || m.contains(i+1, 2, "ET"):
+			// Germanic
+			m.addBoth("K")
+		case m.contains(i+1, 3, "IER"):
+			m.addBoth("J")
+		default:
+			m.add("J", "K")
+		}
+		return i + 2
+	case m.at(i+1) == 'G':
+		m.addBoth("K")
+		return i + 2
+	}
+	m.addBoth("K")
+	return i + 1
+}
+
+func (m *doubleMetaphone) encodeGH(i int) int {
+	switch {
+	case i > 0 && !m.isVowel(i-1):
+		m.addBoth("K")
+	case i == 0:
+		// e.g. "ghislane", "ghost"
+		if m.at(i+2) == 'I' {
+			m.addBoth("J")
+		} else {
+			m.addBoth("K")
+		}
+	case i > 1 && m.contains(i-2, 1, "B", "H", "D") ||
+		i > 2 && m.contains(i-3, 1, "B", "H", "D") ||
+		i > 3 && m.contains(i-4, 1, "B", "H"):
+		// silent. e.g. "Hugh", "bough", "broughton"
+	default:
+		if i > 2 && m.at(i-1) == 'U' && m.contains(i-3, 1, "C", "G", "L", "R", "T") {
+			// e.g. "laugh", "McLaughlin", "cough", "rough"
+			m.addBoth("F")
+		} else if i > 0 && m.at(i-1) != 'I' {
+			m.addBoth("K")
+		}
+	}
+	return i + 2
+}
+
+func (m *doubleMetaphone) encodeJ(i int) int {
+	if m.contains(i, 4, "JOSE") {
+		// Spanish. e.g. "Jose"
+		if i == 0 && len(m.w) == 4 {
+			m.addBoth("H")
+		} else {
+			m.add("J", "H")
+		}
+		return i + 1
+	}
+
+	switch {
+	case i == 0:
+		// e.g. "Yankelovich", "Jankelowicz"
+		m.add("J", "A")
+	case m.isVowel(i-1) && !m.slavoGermanic && (m.at(i+1) == 'A' || m.at(i+1) == 'O'):
+		// Spanish. e.g. "bajador"
+		m.add("J", "H")
+	case i == len(m.w)-1:
+		m.add("J", "")
+	case !m.contains(i+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.contains(i-1, 1, "S", "K", "L"):
+		m.addBoth("J")
+	}
+	return m.skip(i, "J")
+}
+
+func (m *doubleMetaphone) encodeL(i int) int {
+	if m.at(i+1) != 'L' {
+		m.addBoth("L")
+		return i + 1
+	}
+
+	last := len(m.w) - 1
+	if i == last-2 && m.contains(i-1, 4, "ILLO", "ILLA", "ALLE") ||
+		(m.contains(last-1, 2, "AS", "OS") || m.contains(last, 1, "A", "O")) && m.contains(i-1, 4, "ALLE") {
+		// Spanish. e.g. "cabrillo", "gallegos"
+		m.add("L", "")
+	} else {
+		m.addBoth("L")
+	}
+	return i + 2
+}
+
+func (m *doubleMetaphone) encodeS(i int) int {
+	switch {
+	case m.contains(i-1, 3, "ISL", "YSL"):
+		// silent. e.g. "island", "carlisle"
+		return i + 1
+	case i == 0 && m.contains(i, 5, "SUGAR"):
+		m.add("X", "S")
+		return i + 1
+	case m.contains(i, 2, "SH"):
+		if m.contains(i+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			// Germanic
+			m.addBoth("S")
+		} else {
+			m.addBoth("X")
+		}
+		return i + 2
+	case m.contains(i, 3, "SIO", "SIA") || m.contains(i, 4, "SIAN"):
+		// Italian and Armenian
+		if m.slavoGermanic {
+			m.addBoth("S")
+		} else {
+			m.add("S", "X")
+		}
+		return i + 3
+	case i == 0 && m.contains(i+1, 1, "M", "N", "L", "W") || m.contains(i+1, 1, "Z"):
+		// Germanic. e.g. "Smith" matches "Schmidt"
+		m.add("S", "X")
+		return m.skip(i, "Z")
+	case m.contains(i, 2, "SC"):
+		return m.encodeSC(i)
+	}
+
+	if i == len(m.w)-1 && m.contains(i-2, 2, "AI", "OI") {
+		// French. e.g. "resnais", "artois"
+		m.add("", "S")
+	} else {
+		m.addBoth("S")
+	}
+	return m.skip(i, "S", "Z")
+}
+
+func (m *doubleMetaphone) encodeSC(i int) int {
+	switch {
+	case m.at(i+2) == 'H':
+		switch {
+		case m.contains(i+3, 2, "OO", "ER", "EN", "UY", "ED", "EM"):
+			// Dutch. e.g. "school", "schooner"
+			if m.contains(i+3, 2, "ER", "EN") {
+				m.add("X", "SK")
+			} else {
+				m.addBoth("SK")
+			}
+		case i == 0 && !m.isVowel(3) && m.at(3) != 'W':
+			m.add("X", "S")
+		default:
+			m.addBoth("X")
+		}
+	case m.contains(i+2, 1, "I", "E", "Y"):
+		m.addBoth("S")
+	default:
+		m.addBoth("SK")
+	}
+	return i + 3
+}
+
+func (m *doubleMetaphone) encodeT(i int) int {
+	switch {
+	case m.contains(i, 4, "TION"):
+		m.addBoth("X")
+		return i + 3
+	case m.contains(i, 3, "TIA", "TCH"):
+		m.addBoth("X")
+		return i + 3
+	case m.contains(i, 2, "TH") || m.contains(i, 3, "TTH"):
+		if m.contains(i+2, 2, "OM", "AM") || m.contains(0, 3, "SCH") {
+			// e.g. "Thomas", "Thames"
+			m.addBoth("T")
+		} else {
+			m.add("0", "T")
+		}
+		return i + 2
+	}
+	m.addBoth("T")
+	return m.skip(i, "T", "D")
+}
+
+func (m *doubleMetaphone) encodeW(i int) int {
+	switch {
+	case m.contains(i, 2, "WR"):
+		m.addBoth("R")
+		return i + 2
+	case i == 0 && (m.isVowel(i+1) || m.contains(i, 2, "WH")):
+		if m.isVowel(i + 1) {
+			// e.g. "Wasserman" matches "Vasserman"
+			m.add("A", "F")
+		} else {
+			m.addBoth("A")
+		}
+	case i == len(m.w)-1 && m.isVowel(i-1) ||
+		m.contains(i-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.contains(0, 3, "SCH"):
+		// Polish. e.g. "Filipowicz"
+		m.add("", "F")
+	case m.contains(i, 4, "WICZ", "WITZ"):
+		m.add("TS", "FX")
+		return i + 4
+	}
+	return i + 1
+}
+
+func (m *doubleMetaphone) encodeX(i int) int {
+	if i == 0 {
+		// e.g. "Xavier"
+		m.addBoth("S")
+		return i + 1
+	}
+
+	if !(i == len(m.w)-1 && (m.contains(i-3, 3, "IAU", "EAU") || m.contains(i-2, 2, "AU", "OU"))) {
+		// not French. e.g. "breaux"
+		m.addBoth("KS")
+	}
+	return m.skip(i, "C", "X")
+}
+
+func (m *doubleMetaphone) encodeZ(i int) int {
+	if m.at(i+1) == 'H' {
+		// Chinese. e.g. "Zhao"
+		m.addBoth("J")
+		return i + 2
+	}
+
+	if m.contains(i+1, 2, "ZO", "ZI", "ZA") || m.slavoGermanic && i > 0 && m.at(i-1) != 'T' {
+		m.add("S", "TS")
+	} else {
+		m.addBoth("S")
+	}
+	return m.skip(i, "Z")
+}
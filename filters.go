@@ -1,16 +1,30 @@
 package xim
 
 import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
 	"strings"
-	"unicode/utf8"
+	"time"
 
 	"golang.org/x/xerrors"
 )
 
 // Filters - filters builder for extra indexes.
 type Filters struct {
-	m    indexesMap // key=label, value=index set
-	conf *Config
+	m            indexesMap // key=label, value=index set
+	alternatives []filterAlternatives
+	conditions   []PostFilter // conditions to verify with Matcher
+	postFilters  []PostFilter
+	conf         *Config
+	err          error // error of conditions which can't be searched, returned by Build and BuildAll
+}
+
+// filterAlternatives - alternative token sets of a label, one of which has to match.
+type filterAlternatives struct {
+	label   string
+	options [][]string
 }
 
 // NewFilters - creates and initializes a new Filters.
@@ -26,9 +40,7 @@ func NewFilters(conf *Config) *Filters {
 
 func (filters *Filters) add(label string, indexes ...string) {
 	for _, idx := range indexes {
-		if filters.conf.IgnoreCase {
-			idx = strings.ToLower(idx)
-		}
+		idx = filters.conf.normalize(idx)
 
 		if _, ok := filters.m[label]; !ok {
 			filters.m[label] = make(map[string]struct{})
@@ -41,49 +53,531 @@ func (filters *Filters) add(label string, indexes ...string) {
 // Add - adds new filters with a label.
 func (filters *Filters) Add(label string, indexes ...string) *Filters {
 	filters.add(label, indexes...)
+	for _, idx := range indexes {
+		filters.addCondition(PostFilter{Label: label, Match: MatchExact, Value: idx}, false)
+	}
+	return filters
+}
+
+// AddIn - adds a new IN filter of bits with a label.
+// Bits of multiple groups of 4 bits become alternatives built with BuildAll.
+func (filters *Filters) AddIn(label string, in *InBuilder, bits ...Bit) *Filters {
+	options := make([][]string, 0, 1)
+	for _, f := range in.Filters(bits...) {
+		options = append(options, []string{f})
+	}
+	filters.addAlternatives(label, options)
+	filters.addCondition(PostFilter{Label: label, Match: MatchIn, Value: in.mask(bits...)}, false)
+	return filters
+}
+
+// AddTokenized - adds new filters tokenized by t with a label.
+func (filters *Filters) AddTokenized(label string, t Tokenizer, s string) *Filters {
+	filters.add(label, t.FilterTokens(filters.conf.normalize(s))...)
 	return filters
 }
 
 // AddBigrams - adds new bigram filters with a label.
+// A PostFilter is added if the query has multiple words or words longer than 2 characters.
 func (filters *Filters) AddBigrams(label string, s string) *Filters {
-	return filters.AddBiunigrams(label, s)
+	filters.AddTokenized(label, filters.conf.tokenizers().bigrams, s)
+	filters.addCondition(PostFilter{Label: label, Match: MatchPartial, Value: s}, !filters.isExactPartial(s, 2))
+	return filters
 }
 
 // AddBiunigrams - adds new biunigram filters with a label.
+// A PostFilter is added if the query has multiple words or words longer than 2 characters.
 func (filters *Filters) AddBiunigrams(label string, s string) *Filters {
-	if runeLen := utf8.RuneCountInString(s); runeLen == 1 {
-		filters.Add(label, s)
-	} else if runeLen > 1 {
-		filters.Add(label, Bigrams(s)...)
+	filters.AddTokenized(label, filters.conf.tokenizers().biunigrams, s)
+	filters.addCondition(PostFilter{Label: label, Match: MatchPartial, Value: s}, !filters.isExactPartial(s, 2))
+	return filters
+}
+
+// AddNgrams - adds new n-gram filters with a label.
+// Words shorter than n fall back to a shorter gram, which requires indexes saved by Indexes.AddNgrams.
+// A PostFilter is added if the query has multiple words or words longer than n.
+func (filters *Filters) AddNgrams(label string, s string, n int) *Filters {
+	filters.AddTokenized(label, filters.conf.tokenizers().ngrams(n), s)
+	filters.addCondition(PostFilter{Label: label, Match: MatchPartial, Value: s}, !filters.isExactPartial(s, n))
+	return filters
+}
+
+// AddPartial - adds new partial match filters with a label.
+// Words shorter than Config.PartialGrams of the label are searched as is, and longer ones with the n-grams.
+// It requires indexes saved by Indexes.AddPartial, or Indexes.AddBiunigrams for the default.
+// A PostFilter is added if the query has multiple words or words longer than the grams,
+// since the grams can match in different positions.
+func (filters *Filters) AddPartial(label string, s string) *Filters {
+	tokenizers := filters.conf.tokenizers()
+	n := filters.conf.partialGrams(label)
+	filters.AddTokenized(label, tokenizers.ngrams(n), s)
+	filters.addCondition(PostFilter{Label: label, Match: MatchPartial, Value: s}, !filters.isExactPartial(s, n))
+	return filters
+}
+
+// isExactPartial - reports whether n-grams of s match only values containing s,
+// which requires a single word not longer than n since n-grams don't record their positions.
+func (filters *Filters) isExactPartial(s string, n int) bool {
+	tokenizers := filters.conf.tokenizers()
+	words := tokenizers.words(filters.conf.normalize(s))
+	exact := len(words) <= 1
+	for _, w := range words {
+		exact = exact && tokenizers.length(w) <= n
+	}
+	return exact
+}
+
+// AddPhrase - adds new word shingle filters to search the phrase s with a label.
+// size must be the same as Indexes.AddShingles.
+// If s is longer than size words, a PostFilter is added since the shingles can match words in different positions.
+func (filters *Filters) AddPhrase(label string, s string, size int) *Filters {
+	tokenizers := filters.conf.tokenizers()
+	filters.AddTokenized(label, tokenizers.shingles(size), s)
+
+	post := size > 0 && len(tokenizers.words(filters.conf.normalize(s))) > size
+	filters.addCondition(PostFilter{Label: label, Match: MatchPhrase, Value: s}, post)
+	return filters
+}
+
+// AddStems - adds new stem filters of English words with a label.
+func (filters *Filters) AddStems(label string, s string) *Filters {
+	return filters.AddTokenized(label, filters.conf.tokenizers().stems, s)
+}
+
+// AddPhonetic - adds new phonetic code filters of words with a label.
+// It matches indexes saved by Indexes.AddPhonetic with the same Phonetic.
+// For PhoneticDoubleMetaphone, the primary and alternate codes of each word become alternatives built with BuildAll,
+// and only primary codes are searched for the rest of words once the alternatives exceed MaxFilterAlternatives.
+func (filters *Filters) AddPhonetic(label string, s string, p Phonetic) *Filters {
+	if p != PhoneticDoubleMetaphone {
+		return filters.AddTokenized(label, filters.conf.tokenizers().phonetic(p), s)
+	}
+
+	size := filters.alternativeCount()
+	for _, w := range filters.conf.tokenizers().words(filters.conf.normalize(s)) {
+		codes := p.codes(w)
+		if size*len(codes) > MaxFilterAlternatives {
+			codes = codes[:1]
+		}
+		options := make([][]string, 0, len(codes))
+		for _, c := range codes {
+			options = append(options, []string{c})
+		}
+		filters.addAlternatives(label, options)
+		size *= len(options)
 	}
 	return filters
 }
 
+// AddFuzzy - adds new filters to search words within the edit distance with a label.
+// distance must be the same as Indexes.AddDeletions.
+// Each word produces alternatives of its deletion variants, so the filters have to be built with BuildAll.
+// Words are added from ones with fewer variants as long as the alternatives don't exceed MaxFilterAlternatives,
+// and the rest of words are verified only by the PostFilter.
+// The PostFilter is always added since words sharing a variant can be farther than the distance.
+func (filters *Filters) AddFuzzy(label string, s string, distance int) *Filters {
+	tokenizers := filters.conf.tokenizers()
+	tokenizer := tokenizers.deletions(distance)
+
+	variants := make([][]string, 0, 8)
+	for _, w := range tokenizers.words(filters.conf.normalize(s)) {
+		variants = append(variants, tokenizer.IndexTokens(w))
+	}
+	sort.SliceStable(variants, func(i, j int) bool {
+		return len(variants[i]) < len(variants[j])
+	})
+
+	size := filters.alternativeCount()
+	for _, tokens := range variants {
+		if size*len(tokens) > MaxFilterAlternatives {
+			continue
+		}
+		options := make([][]string, 0, len(tokens))
+		for _, t := range tokens {
+			options = append(options, []string{t})
+		}
+		filters.addAlternatives(label, options)
+		size *= len(options)
+	}
+
+	filters.addCondition(PostFilter{
+		Label:    label,
+		Match:    MatchFuzzy,
+		Value:    s,
+		Distance: distance,
+	}, true)
+	return filters
+}
+
 // AddPrefix - adds a new prefix filters with a label.
+// If s is longer than the maximum length of Config.AffixBounds, it's truncated and a PostFilter is added.
+// If s is shorter than the minimum length, Build and BuildAll return an error since such short affixes are not saved.
 func (filters *Filters) AddPrefix(label string, s string) *Filters {
-	// don't need to split prefixes on filters
-	return filters.Add(label, s)
+	return filters.addAffix(label, s, MatchPrefix)
 }
 
 // AddSuffix - adds a new suffix filters with a label.
+// If s is longer than the maximum length of Config.AffixBounds, it's truncated and a PostFilter is added.
+// If s is shorter than the minimum length, Build and BuildAll return an error since such short affixes are not saved.
 func (filters *Filters) AddSuffix(label string, s string) *Filters {
-	// don't need to split suffixes on filters
-	return filters.Add(label, s)
+	return filters.addAffix(label, s, MatchSuffix)
+}
+
+// AddPhrasePrefix - adds a new prefix filter of the whole s with a label.
+// If s is longer than the maximum length of Config.AffixBounds, it's truncated and a PostFilter is added.
+// If s is shorter than the minimum length, Build and BuildAll return an error since such short affixes are not saved.
+func (filters *Filters) AddPhrasePrefix(label string, s string) *Filters {
+	return filters.addAffix(label, s, MatchPhrasePrefix)
+}
+
+// AddPhraseSuffix - adds a new suffix filter of the whole s with a label.
+// If s is longer than the maximum length of Config.AffixBounds, it's truncated and a PostFilter is added.
+// If s is shorter than the minimum length, Build and BuildAll return an error since such short affixes are not saved.
+func (filters *Filters) AddPhraseSuffix(label string, s string) *Filters {
+	return filters.addAffix(label, s, MatchPhraseSuffix)
+}
+
+// AddWildcard - adds new filters to search words matching the pattern with labels.
+// '*' in the pattern matches any sequence of characters and '?' matches a single character.
+// e.g. "ab*cd", "*ing", "ha?ry".
+// The literal start and end of the pattern are searched with prefix and suffix labels,
+// and the rest with the partial label.
+// A PostFilter is added since the filters can match different words, so results should be verified with WildcardMatch.
+func (filters *Filters) AddWildcard(labels WildcardLabels, pattern string) *Filters {
+	tokenizers := filters.conf.tokenizers()
+	p := parseWildcard(tokenizers.units(filters.conf.normalize(pattern)))
+
+	for i, fragment := range p.fragments {
+		s := strings.Join(fragment, "")
+
+		// shorter prefixes and suffixes than the minimum length are not saved
+		affixed := false
+		if i == 0 && p.prefix && labels.Prefix != "" {
+			if bounds := filters.conf.AffixBounds[labels.Prefix]; len(fragment) >= bounds.Min {
+				filters.AddTokenized(labels.Prefix, tokenizers.prefixes(bounds), s)
+				affixed = true
+			}
+		}
+		if i == len(p.fragments)-1 && p.suffix && labels.Suffix != "" {
+			if bounds := filters.conf.AffixBounds[labels.Suffix]; len(fragment) >= bounds.Min {
+				filters.AddTokenized(labels.Suffix, tokenizers.suffixes(bounds), s)
+				affixed = true
+			}
+		}
+		if !affixed && labels.Partial != "" {
+			filters.AddTokenized(labels.Partial, tokenizers.biunigrams, s)
+		}
+	}
+
+	filters.addPostFilter(labels.postFilterLabel(), MatchWildcard, pattern)
+	return filters
+}
+
+// AddRegexp - adds new bigram filters to search values matching re with a label.
+// Bigrams which every match contains are extracted from re, and alternations become alternatives built with BuildAll.
+// It requires indexes saved by Indexes.AddBigrams or Indexes.AddBiunigrams.
+// A PostFilter is always added since the filters only narrow down candidates.
+func (filters *Filters) AddRegexp(label string, re *regexp.Regexp) *Filters {
+	filters.addAlternatives(label, filters.conf.regexpBigrams(re))
+	filters.addPostFilter(label, MatchRegexp, re.String())
+	return filters
+}
+
+func (filters *Filters) addAffix(label string, s string, match MatchKind) *Filters {
+	tokenizers := filters.conf.tokenizers()
+	bounds := filters.conf.AffixBounds[label]
+
+	var tokenizer Tokenizer
+	switch match {
+	case MatchPrefix:
+		tokenizer = tokenizers.prefixes(bounds)
+	case MatchSuffix:
+		tokenizer = tokenizers.suffixes(bounds)
+	case MatchPhrasePrefix:
+		tokenizer = tokenizers.phrases(false, bounds)
+	case MatchPhraseSuffix:
+		tokenizer = tokenizers.phrases(true, bounds)
+	}
+	filters.AddTokenized(label, tokenizer, s)
+
+	query := filters.conf.normalize(s)
+	words := tokenizers.words(query)
+	if match == MatchPhrasePrefix || match == MatchPhraseSuffix {
+		query = normalizePhrase(query)
+		words = []string{query}
+	}
+	// shorter affixes than the minimum length are not saved, so they match only whole words of the same length
+	for _, w := range words {
+		if l := tokenizers.length(w); l > 0 && l < bounds.Min && filters.err == nil {
+			filters.err = xerrors.Errorf("query %q of label %q is shorter than AffixBounds.Min: %d", w, label, bounds.Min)
+		}
+	}
+	filters.addCondition(PostFilter{Label: label, Match: match, Value: s}, bounds.exceeds(tokenizers.length(query)))
+	return filters
+}
+
+// alternativeCount - returns the number of filters built by BuildAll, which is capped just above MaxFilterAlternatives.
+func (filters *Filters) alternativeCount() int {
+	size := 1
+	for _, a := range filters.alternatives {
+		size *= len(a.options)
+		if size > MaxFilterAlternatives {
+			return MaxFilterAlternatives + 1
+		}
+	}
+	return size
+}
+
+// addAlternatives - adds filters one of whose options has to match.
+func (filters *Filters) addAlternatives(label string, options [][]string) {
+	switch len(options) {
+	case 0:
+		return
+	case 1:
+		filters.add(label, options[0]...)
+		return
+	}
+
+	normalized := make([][]string, 0, len(options))
+	for _, tokens := range options {
+		o := make([]string, 0, len(tokens))
+		for _, t := range tokens {
+			o = append(o, filters.conf.normalize(t))
+		}
+		normalized = append(normalized, o)
+	}
+	filters.alternatives = append(filters.alternatives, filterAlternatives{
+		label:   label,
+		options: normalized,
+	})
+}
+
+func (filters *Filters) addPostFilter(label string, match MatchKind, s string) {
+	filters.addCondition(PostFilter{Label: label, Match: match, Value: s}, true)
+}
+
+// addCondition - adds a condition for Matcher, and also for PostFilters if post is true.
+func (filters *Filters) addCondition(cond PostFilter, post bool) {
+	filters.conditions = append(filters.conditions, cond)
+	if post {
+		filters.postFilters = append(filters.postFilters, cond)
+	}
+}
+
+// PostFilters - returns conditions to verify search results since they can contain false positives.
+func (filters *Filters) PostFilters() []PostFilter {
+	return append([]PostFilter(nil), filters.postFilters...)
+}
+
+// AddRange - adds new filters to search numbers within [lo, hi) with a label.
+// spec must be the same as Indexes.AddNumberRange, and the range is clamped into it.
+// The range is split into the fewest buckets, and multiple buckets become alternatives built with BuildAll.
+// If it needs more buckets than MaxFilterAlternatives, coarser buckets covering the range are used
+// and a PostFilter is added.
+// It panics if the spec is invalid.
+func (filters *Filters) AddRange(label string, lo, hi int64, spec NumberRangeSpec) *Filters {
+	options, exact := numberRangeFilterOptions(lo, hi, spec)
+	filters.addAlternatives(label, options)
+	filters.addCondition(PostFilter{
+		Label: label,
+		Match: MatchRange,
+		Value: fmt.Sprintf("%d<=v<%d", lo, hi),
+		Low:   lo,
+		High:  hi,
+	}, !exact)
+	return filters
+}
+
+// AddTimeRange - adds new filters to search time within [start, end) with a label.
+// spec must be the same as Indexes.AddTimeBuckets.
+// The range is split into the fewest calendar buckets, and multiple buckets become alternatives built with BuildAll.
+// If the range is not aligned to the buckets or needs more buckets than MaxFilterAlternatives,
+// coarser buckets covering the range are used and a PostFilter is added.
+// Hours repeated by DST transitions are also verified by the PostFilter,
+// and only the PostFilter is added if the range needs more buckets than MaxFilterAlternatives even by years.
+// It panics if the spec is invalid.
+func (filters *Filters) AddTimeRange(label string, start, end time.Time, spec TimeBucketSpec) *Filters {
+	options, exact := timeRangeFilterOptions(start, end, spec)
+	filters.addAlternatives(label, options)
+	filters.addCondition(PostFilter{
+		Label: label,
+		Match: MatchRange,
+		Value: fmt.Sprintf("%s<=v<%s", start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano)),
+		Low:   unixNano(start),
+		High:  unixNano(end),
+	}, !exact)
+	return filters
+}
+
+// AddOverlap - adds new filters to search intervals overlapping [start, end) with a label.
+// spec must be the same as Indexes.AddInterval.
+// Buckets become alternatives built with BuildAll, and a PostFilter is added since intervals are indexed by buckets.
+// If the range needs more buckets than MaxFilterAlternatives even by years, only the PostFilter is added.
+// It panics if the spec is invalid.
+func (filters *Filters) AddOverlap(label string, start, end time.Time, spec TimeBucketSpec) *Filters {
+	filters.addAlternatives(label, intervalFilterOptions(start, end, spec))
+	filters.addCondition(PostFilter{
+		Label: label,
+		Match: MatchOverlap,
+		Value: fmt.Sprintf("%s<=v<%s", start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano)),
+		Low:   unixNano(start),
+		High:  unixNano(end),
+	}, true)
+	return filters
+}
+
+// AddActiveAt - adds new filters to search intervals containing t with a label.
+// spec must be the same as Indexes.AddInterval.
+// It panics if the spec is invalid.
+func (filters *Filters) AddActiveAt(label string, t time.Time, spec TimeBucketSpec) *Filters {
+	return filters.AddOverlap(label, t, t.Add(time.Nanosecond), spec)
+}
+
+// AddGeoWithin - adds new filters to search locations within radius meters from the location with a label.
+// maxPrecision must be the same as Indexes.AddGeo.
+// Geohash cells covering the circle become alternatives built with BuildAll, and a PostFilter checks the distance.
+// The cells are coarsened if they are more than MaxFilterAlternatives,
+// and no cells are added if even precision 1 needs more.
+func (filters *Filters) AddGeoWithin(label string, lat, lng, radius float64, maxPrecision int) *Filters {
+	filters.addGeoCells(label, circleBox(lat, lng, radius), maxPrecision)
+	filters.addCondition(PostFilter{
+		Label: label,
+		Match: MatchGeoWithin,
+		Value: fmt.Sprintf("distance(%g,%g)<=%g", lat, lng, radius),
+		Area:  [4]float64{lat, lng, radius},
+	}, true)
+	return filters
+}
+
+// AddGeoBox - adds new filters to search locations within the box with a label.
+// The box crosses the antimeridian if minLng > maxLng. maxPrecision must be the same as Indexes.AddGeo.
+// Geohash cells covering the box become alternatives built with BuildAll, and a PostFilter checks the box.
+// The cells are coarsened if they are more than MaxFilterAlternatives,
+// and no cells are added if even precision 1 needs more.
+func (filters *Filters) AddGeoBox(label string, minLat, minLng, maxLat, maxLng float64, maxPrecision int) *Filters {
+	box := geoBox{minLat: minLat, minLng: minLng, maxLat: maxLat, maxLng: maxLng}
+	if minLat > maxLat {
+		filters.add(label, rangeNone)
+	} else {
+		filters.addGeoCells(label, box, maxPrecision)
+	}
+	filters.addCondition(PostFilter{
+		Label: label,
+		Match: MatchGeoBox,
+		Value: fmt.Sprintf("(%g,%g)-(%g,%g)", minLat, minLng, maxLat, maxLng),
+		Area:  [4]float64{minLat, minLng, maxLat, maxLng},
+	}, true)
+	return filters
+}
+
+func (filters *Filters) addGeoCells(label string, box geoBox, maxPrecision int) {
+	cells := geoBoxCells(box, maxPrecision)
+	options := make([][]string, 0, len(cells))
+	for _, c := range cells {
+		options = append(options, []string{c})
+	}
+	filters.addAlternatives(label, options)
+}
+
+// AddCIDR - adds new filters to search IP addresses within the cidr with a label.
+// The cidr is split into finer indexed prefixes as alternatives built with BuildAll
+// if they are not more than MaxFilterAlternatives,
+// or the nearest coarser indexed prefix is searched and a PostFilter is added.
+func (filters *Filters) AddCIDR(label string, cidr *net.IPNet) *Filters {
+	options, exact := cidrFilterOptions(cidr, filters.conf.ipPrefixLengths(label))
+	filters.addAlternatives(label, options)
+	filters.addCondition(PostFilter{
+		Label: label,
+		Match: MatchCIDR,
+		Value: cidr.String(),
+	}, !exact)
+	return filters
+}
+
+// MayContainFalsePositives - reports whether search results can contain values which don't match the conditions.
+// They should be verified with PostFilters if so.
+func (filters *Filters) MayContainFalsePositives() bool {
+	return len(filters.postFilters) > 0
 }
 
 // AddSomething - adds new filter with a label.
 // The indexes can be a slice or a string convertible value.
 func (filters *Filters) AddSomething(label string, indexes interface{}) *Filters {
 	addSomething(filters, label, indexes)
+	for _, idx := range somethingTokens(indexes) {
+		filters.addCondition(PostFilter{Label: label, Match: MatchExact, Value: idx}, false)
+	}
 	return filters
 }
 
 // Build - builds filters to save.
+// It fails if the filters have alternatives, which have to be built with BuildAll.
 func (filters *Filters) Build() (map[string]bool, error) {
-	built := buildIndexes(filters.m, filters.conf.CompositeIdxLabels)
+	if filters.err != nil {
+		return nil, filters.err
+	}
+	if len(filters.alternatives) > 0 {
+		return nil, xerrors.New("filters have alternatives, use BuildAll instead")
+	}
+	return filters.build(filters.m)
+}
+
+// BuildAll - builds all the alternative filters.
+// Search results are the union of results of the filters.
+// Filters without alternatives are built into a single map.
+func (filters *Filters) BuildAll() ([]map[string]bool, error) {
+	if filters.err != nil {
+		return nil, filters.err
+	}
+
+	size := filters.alternativeCount()
+	if size > MaxFilterAlternatives {
+		return nil, xerrors.Errorf("number of alternative filters exceeds %d", MaxFilterAlternatives)
+	}
+
+	builtList := make([]map[string]bool, 0, size)
+	// choices[i] is the index of the option chosen for alternatives[i]
+	choices := make([]int, len(filters.alternatives))
+	for {
+		m := filters.m
+		if len(filters.alternatives) > 0 {
+			m = m.clone()
+			for i, a := range filters.alternatives {
+				if _, ok := m[a.label]; !ok {
+					m[a.label] = make(map[string]struct{})
+				}
+				for _, t := range a.options[choices[i]] {
+					m[a.label][t] = struct{}{}
+				}
+			}
+		}
+
+		built, err := filters.build(m)
+		if err != nil {
+			return nil, err
+		}
+		builtList = append(builtList, built)
+
+		// next combination
+		i := len(choices) - 1
+		for ; i >= 0; i-- {
+			choices[i]++
+			if choices[i] < len(filters.alternatives[i].options) {
+				break
+			}
+			choices[i] = 0
+		}
+		if i < 0 {
+			return builtList, nil
+		}
+	}
+}
+
+func (filters *Filters) build(m indexesMap) (map[string]bool, error) {
+	built := buildIndexes(m, filters.conf.CompositeIdxLabels)
 
 	if len(filters.conf.CompositeIdxLabels) > 1 {
-		cis, err := createCompositeIndexes(filters.conf.CompositeIdxLabels, filters.m, true)
+		cis, err := createCompositeIndexes(filters.conf.CompositeIdxLabels, m, true)
 		if err != nil {
 			return nil, err
 		}
@@ -111,3 +605,12 @@ func (filters Filters) MustBuild() map[string]bool {
 	}
 	return built
 }
+
+// MustBuildAll - builds all the alternative filters and panics with error.
+func (filters Filters) MustBuildAll() []map[string]bool {
+	builtList, err := filters.BuildAll()
+	if err != nil {
+		panic(err)
+	}
+	return builtList
+}
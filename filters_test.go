@@ -3,6 +3,7 @@ package xim
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"testing"
 	"time"
 )
@@ -71,6 +72,102 @@ func TestAddBiunigramsFilter(t *testing.T) {
 	assertBuiltIndex(t, built, expected)
 }
 
+func TestGramFiltersMayContainFalsePositives(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		filter   *Filters
+		expected bool
+	}{
+		{"bigrams of a word <= 2", NewFilters(nil).AddBigrams("t", "ab"), false},
+		{"bigrams of a word > 2", NewFilters(nil).AddBigrams("t", "abcab"), true},
+		{"biunigrams of a word <= 2", NewFilters(nil).AddBiunigrams("t", "a"), false},
+		{"biunigrams of a word > 2", NewFilters(nil).AddBiunigrams("t", "abcab"), true},
+		{"biunigrams of words", NewFilters(nil).AddBiunigrams("t", "ab cd"), true},
+		{"ngrams of a word <= n", NewFilters(nil).AddNgrams("t", "abc", 3), false},
+		{"ngrams of a word > n", NewFilters(nil).AddNgrams("t", "abcd", 3), true},
+	} {
+		if actual := c.filter.MayContainFalsePositives(); actual != c.expected {
+			t.Errorf("%s: expected: %v, but was: %v", c.name, c.expected, actual)
+		}
+	}
+
+	// "abcab" shares all the bigrams with "xabc cab"
+	built := NewIndexes(nil).AddBiunigrams("t", "xabc cab").MustBuild()
+	filter := NewFilters(nil).AddBiunigrams("t", "abcab")
+	for builtFilter := range filter.MustBuild() {
+		if !built[builtFilter] {
+			t.Fatalf("filter: %s not contains", builtFilter)
+		}
+	}
+	if filter.Matcher().Match(map[string]interface{}{"t": "xabc cab"}) {
+		t.Errorf("expected: not matched by the PostFilter, but was: matched")
+	}
+}
+
+func TestAddNgramsFilter(t *testing.T) {
+	t.Run("words >= n characters", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddNgrams("label1", "abcd dあいb", 3)
+
+		built := filter.MustBuild()
+		assertBuiltFilter(t, built, map[string]bool{
+			"label1 abc": true,
+			"label1 bcd": true,
+			"label1 dあい": true,
+			"label1 あいb": true,
+		})
+	})
+
+	t.Run("words < n characters", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddNgrams("label1", "ab c", 3)
+
+		built := filter.MustBuild()
+		assertBuiltFilter(t, built, map[string]bool{
+			"label1 ab": true,
+			"label1 c":  true,
+		})
+	})
+}
+
+func TestAddPhraseFilter(t *testing.T) {
+	t.Run("words == size", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddPhrase("label1", "new  york", 2)
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 new york": true,
+		})
+		if postFilters := filter.PostFilters(); len(postFilters) != 0 {
+			t.Errorf("unexpected, actual: `%v`, expected: no post filters", postFilters)
+		}
+	})
+
+	t.Run("words < size", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddPhrase("label1", "pizza", 2)
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 pizza": true,
+		})
+	})
+
+	t.Run("words > size", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddPhrase("label1", "new york pizza", 2)
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 new york":   true,
+			"label1 york pizza": true,
+		})
+
+		expected := []PostFilter{{Label: "label1", Match: MatchPhrase, Value: "new york pizza"}}
+		if postFilters := filter.PostFilters(); !reflect.DeepEqual(postFilters, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", postFilters, expected)
+		}
+	})
+}
+
 func TestAddPrefixFilter(t *testing.T) {
 	filter := NewFilters(nil)
 	filter.AddPrefix("label1", "abc dあいbCh")
@@ -95,6 +192,67 @@ func TestAddSuffixFilter(t *testing.T) {
 	})
 }
 
+func TestAddPrefixFilterWithinBounds(t *testing.T) {
+	conf := &Config{AffixBounds: map[string]LengthBounds{
+		"label1": {Max: 3},
+		"label2": {Max: 3},
+	}}
+
+	t.Run("query <= max", func(t *testing.T) {
+		filter := NewFilters(conf)
+		filter.AddPrefix("label1", "abc")
+		filter.AddSuffix("label2", "あいb")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 abc": true,
+			"label2 あいb": true,
+		})
+		if postFilters := filter.PostFilters(); len(postFilters) != 0 {
+			t.Errorf("unexpected, actual: `%v`, expected: no post filters", postFilters)
+		}
+	})
+
+	t.Run("query > max", func(t *testing.T) {
+		filter := NewFilters(conf)
+		filter.AddPrefix("label1", "abcde")
+		filter.AddSuffix("label2", "dあいbC")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 abc": true,
+			"label2 いbC": true,
+		})
+
+		expected := []PostFilter{
+			{Label: "label1", Match: MatchPrefix, Value: "abcde"},
+			{Label: "label2", Match: MatchSuffix, Value: "dあいbC"},
+		}
+		if postFilters := filter.PostFilters(); !reflect.DeepEqual(postFilters, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", postFilters, expected)
+		}
+	})
+
+	t.Run("query < min", func(t *testing.T) {
+		conf := &Config{AffixBounds: map[string]LengthBounds{"label1": {Min: 3}}}
+
+		for _, filter := range []*Filters{
+			NewFilters(conf).AddPrefix("label1", "ha"),
+			NewFilters(conf).AddSuffix("label1", "harry ha"),
+			NewFilters(conf).AddPhrasePrefix("label1", " h "),
+		} {
+			if _, err := filter.Build(); err == nil {
+				t.Error("error = nil, wants != nil")
+			}
+			if _, err := filter.BuildAll(); err == nil {
+				t.Error("error = nil, wants != nil")
+			}
+		}
+
+		if _, err := NewFilters(conf).AddPrefix("label1", "har").Build(); err != nil {
+			t.Errorf("error = %s, wants = nil", err)
+		}
+	})
+}
+
 func TestAddSomethingFilter(t *testing.T) {
 	filter := NewFilters(nil)
 	filter.AddSomething("label1", []string{"abc dあいbCh", "abc debch iJあdeN"})
@@ -296,6 +454,28 @@ func TestFilterConfigIgnoreCase(t *testing.T) {
 	assertBuiltIndex(t, built, expected)
 }
 
+func TestFilterConfigNormalization(t *testing.T) {
+	t.Run("NormalizationWidth", func(t *testing.T) {
+		filter := NewFilters(&Config{Normalization: NormalizationWidth})
+		filter.Add("label1", "ＡＢＣ１２３")
+		filter.AddBiunigrams("label2", "ｶﾞ")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 ABC123": true,
+			"label2 ガ":      true,
+		})
+	})
+
+	t.Run("NormalizationNFKC", func(t *testing.T) {
+		filter := NewFilters(&Config{Normalization: NormalizationNFKC, IgnoreCase: true})
+		filter.AddPrefix("label1", "ＡＢＣ①")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 abc1": true,
+		})
+	})
+}
+
 func TestFilterConfigSaveNoFiltersIndex(t *testing.T) {
 	t.Run("No filter Add", func(t *testing.T) {
 		filter := NewFilters(&Config{SaveNoFiltersIndex: true})
@@ -325,3 +505,272 @@ func assertBuiltFilter(t *testing.T, actual, expected map[string]bool) {
 		t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
 	}
 }
+
+func TestAddFuzzyFilter(t *testing.T) {
+	t.Run("alternatives", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.Add("label1", "a")
+		filter.AddFuzzy("label2", "abc", 1)
+
+		builtList := filter.MustBuildAll()
+		if len(builtList) != 4 {
+			t.Fatalf("len(builtList) expected: %d, but was: %d", 4, len(builtList))
+		}
+		variants := make(map[string]bool)
+		for _, built := range builtList {
+			if len(built) != 2 || !built["label1 a"] {
+				t.Errorf("unexpected, actual: `%v`", built)
+			}
+			for idx := range built {
+				variants[idx] = true
+			}
+		}
+		assertBuiltFilter(t, variants, map[string]bool{
+			"label1 a":   true,
+			"label2 abc": true,
+			"label2 ab":  true,
+			"label2 ac":  true,
+			"label2 bc":  true,
+		})
+
+		expected := []PostFilter{{Label: "label2", Match: MatchFuzzy, Value: "abc", Distance: 1}}
+		if postFilters := filter.PostFilters(); !reflect.DeepEqual(postFilters, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", postFilters, expected)
+		}
+	})
+
+	t.Run("Build with alternatives", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddFuzzy("label1", "abc", 1)
+
+		if _, err := filter.Build(); err == nil {
+			t.Errorf("expected: error, but was: nil")
+		}
+	})
+
+	t.Run("single word", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddFuzzy("label1", "a", 1)
+
+		built := filter.MustBuild()
+		assertBuiltFilter(t, built, map[string]bool{
+			"label1 a": true,
+		})
+	})
+
+	t.Run("too many alternatives", func(t *testing.T) {
+		for _, c := range []struct {
+			s        string
+			distance int
+		}{
+			{"robert brown", 1},
+			{"katherine smith", 1},
+			{"alexander", 2},
+		} {
+			filter := NewFilters(nil)
+			filter.AddFuzzy("label1", c.s, c.distance)
+
+			builtList, err := filter.BuildAll()
+			if err != nil {
+				t.Errorf("%s: error = %s, wants = nil", c.s, err)
+			}
+			if len(builtList) > MaxFilterAlternatives {
+				t.Errorf("%s: len(builtList) expected: <= %d, but was: %d", c.s, MaxFilterAlternatives, len(builtList))
+			}
+			if !filter.MayContainFalsePositives() {
+				t.Errorf("%s: expected: false positives", c.s)
+			}
+		}
+	})
+}
+
+func TestBuildAllTooManyAlternatives(t *testing.T) {
+	filter := NewFilters(nil)
+	filter.AddRange("label1", 1, 31, NumberRangeSpec{Min: 0, Max: 32})
+	filter.AddRange("label2", 1, 31, NumberRangeSpec{Min: 0, Max: 32})
+
+	if _, err := filter.BuildAll(); err == nil {
+		t.Errorf("expected: error, but was: nil")
+	}
+}
+
+func TestBuildAllWithoutAlternatives(t *testing.T) {
+	filter := NewFilters(nil)
+	filter.Add("label1", "a")
+
+	builtList := filter.MustBuildAll()
+	if len(builtList) != 1 {
+		t.Fatalf("len(builtList) expected: %d, but was: %d", 1, len(builtList))
+	}
+	assertBuiltFilter(t, builtList[0], filter.MustBuild())
+}
+
+func TestAddWildcardFilter(t *testing.T) {
+	labels := WildcardLabels{Prefix: "p", Suffix: "s", Partial: "b"}
+
+	t.Run("prefix and suffix", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddWildcard(labels, "ab*cde")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"p ab":  true,
+			"s cde": true,
+		})
+		expected := []PostFilter{{Label: "b", Match: MatchWildcard, Value: "ab*cde"}}
+		if postFilters := filter.PostFilters(); !reflect.DeepEqual(postFilters, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", postFilters, expected)
+		}
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddWildcard(labels, "*ing?a*z")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"b in": true,
+			"b ng": true,
+			"b a":  true,
+			"s z":  true,
+		})
+	})
+
+	t.Run("no wildcards", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddWildcard(labels, "abc")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"p abc": true,
+			"s abc": true,
+		})
+	})
+
+	t.Run("without prefix and suffix labels", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddWildcard(WildcardLabels{Partial: "b"}, "ha?ry")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"b ha": true,
+			"b ry": true,
+		})
+	})
+
+	t.Run("shorter than the minimum length", func(t *testing.T) {
+		filter := NewFilters(&Config{AffixBounds: map[string]LengthBounds{"p": {Min: 3}}})
+		filter.AddWildcard(labels, "ab*")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"b ab": true,
+		})
+	})
+}
+
+func TestAddRegexpFilter(t *testing.T) {
+	t.Run("single plan", func(t *testing.T) {
+		filter := NewFilters(&Config{IgnoreCase: true})
+		filter.AddRegexp("label1", regexp.MustCompile(`(?i)timeout \d+ms`))
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 ti": true,
+			"label1 im": true,
+			"label1 me": true,
+			"label1 eo": true,
+			"label1 ou": true,
+			"label1 ut": true,
+			"label1 ms": true,
+		})
+		expected := []PostFilter{{Label: "label1", Match: MatchRegexp, Value: `(?i)timeout \d+ms`}}
+		if postFilters := filter.PostFilters(); !reflect.DeepEqual(postFilters, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", postFilters, expected)
+		}
+	})
+
+	t.Run("alternatives", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddRegexp("label1", regexp.MustCompile(`(ab|cd)`))
+
+		builtList := filter.MustBuildAll()
+		if len(builtList) != 2 {
+			t.Fatalf("len(builtList) expected: %d, but was: %d", 2, len(builtList))
+		}
+		assertBuiltFilter(t, builtList[0], map[string]bool{"label1 ab": true})
+		assertBuiltFilter(t, builtList[1], map[string]bool{"label1 cd": true})
+	})
+
+	t.Run("no bigrams", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddRegexp("label1", regexp.MustCompile(`a.*`))
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{})
+		if postFilters := filter.PostFilters(); len(postFilters) != 1 {
+			t.Errorf("len(postFilters) expected: %d, but was: %d", 1, len(postFilters))
+		}
+	})
+}
+
+func TestAddPartialFilter(t *testing.T) {
+	t.Run("single character", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddPartial("label1", "a")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 a": true,
+		})
+		if filter.MayContainFalsePositives() {
+			t.Errorf("expected: no false positives, but was: %v", filter.PostFilters())
+		}
+	})
+
+	t.Run("as long as grams", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddPartial("label1", "ab")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 ab": true,
+		})
+		if filter.MayContainFalsePositives() {
+			t.Errorf("expected: no false positives, but was: %v", filter.PostFilters())
+		}
+	})
+
+	t.Run("longer than grams", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddPartial("label1", "abc")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 ab": true,
+			"label1 bc": true,
+		})
+		expected := []PostFilter{{Label: "label1", Match: MatchPartial, Value: "abc"}}
+		if postFilters := filter.PostFilters(); !reflect.DeepEqual(postFilters, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", postFilters, expected)
+		}
+		if !filter.MayContainFalsePositives() {
+			t.Errorf("expected: false positives, but was: no false positives")
+		}
+	})
+
+	t.Run("multiple words", func(t *testing.T) {
+		filter := NewFilters(nil)
+		filter.AddPartial("label1", "a b")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 a": true,
+			"label1 b": true,
+		})
+		if !filter.MayContainFalsePositives() {
+			t.Errorf("expected: false positives, but was: no false positives")
+		}
+	})
+
+	t.Run("PartialGrams", func(t *testing.T) {
+		filter := NewFilters(&Config{PartialGrams: map[string]int{"label1": 3}})
+		filter.AddPartial("label1", "abc")
+
+		assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+			"label1 abc": true,
+		})
+		if filter.MayContainFalsePositives() {
+			t.Errorf("expected: no false positives, but was: %v", filter.PostFilters())
+		}
+	})
+}
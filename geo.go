@@ -0,0 +1,163 @@
+package xim
+
+import (
+	"math"
+)
+
+const (
+	MaxGeohashPrecision = 12 // maximum precision of geohashes.
+
+	earthRadius   = 6371008.8 // mean radius of the earth in meters
+	geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+// clampGeohashPrecision - clamps precision into [1, MaxGeohashPrecision].
+func clampGeohashPrecision(precision int) int {
+	switch {
+	case precision < 1:
+		return 1
+	case precision > MaxGeohashPrecision:
+		return MaxGeohashPrecision
+	}
+	return precision
+}
+
+// geohashBits - returns the number of bits of latitude and longitude in a geohash of the precision.
+func geohashBits(precision int) (latBits, lngBits uint) {
+	bits := uint(precision) * 5
+	return bits / 2, (bits + 1) / 2
+}
+
+// geohashIndex - returns the index of the cell containing v in [min, min+span) divided into 2^bits cells.
+func geohashIndex(v, min, span float64, bits uint) uint64 {
+	cells := uint64(1) << bits
+	i := math.Floor((v - min) / span * float64(cells))
+	switch {
+	case i < 0:
+		return 0
+	case i >= float64(cells):
+		return cells - 1
+	}
+	return uint64(i)
+}
+
+// geohashCell - returns the geohash of the cell of the indices.
+func geohashCell(latIdx, lngIdx uint64, precision int) string {
+	latBits, lngBits := geohashBits(precision)
+	buf := make([]byte, precision)
+	for c := range buf {
+		var v byte
+		for b := 0; b < 5; b++ {
+			v <<= 1
+			// bits of longitude and latitude are interleaved starting with longitude
+			if (c*5+b)%2 == 0 {
+				lngBits--
+				v |= byte(lngIdx >> lngBits & 1)
+			} else {
+				latBits--
+				v |= byte(latIdx >> latBits & 1)
+			}
+		}
+		buf[c] = geohashBase32[v]
+	}
+	return string(buf)
+}
+
+// Geohash - returns the geohash of the location with the precision, which is clamped into [1, MaxGeohashPrecision].
+func Geohash(lat, lng float64, precision int) string {
+	precision = clampGeohashPrecision(precision)
+	latBits, lngBits := geohashBits(precision)
+	return geohashCell(geohashIndex(lat, -90, 180, latBits), geohashIndex(lng, -180, 360, lngBits), precision)
+}
+
+// GeoDistance - returns the great-circle distance between two locations in meters.
+func GeoDistance(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLng := (lng2 - lng1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// GeoTokens - returns geohash prefix tokens of the location from precision 1 to maxPrecision.
+func GeoTokens(lat, lng float64, maxPrecision int) []string {
+	hash := Geohash(lat, lng, maxPrecision)
+	tokens := make([]string, 0, len(hash))
+	for i := 1; i <= len(hash); i++ {
+		tokens = append(tokens, hash[:i])
+	}
+	return tokens
+}
+
+// geoBox - a bounding box of locations. It crosses the antimeridian if minLng > maxLng.
+type geoBox struct {
+	minLat, minLng, maxLat, maxLng float64
+}
+
+// contains - reports whether the box contains the location.
+func (b geoBox) contains(lat, lng float64) bool {
+	if lat < b.minLat || lat > b.maxLat {
+		return false
+	}
+	if b.minLng <= b.maxLng {
+		return b.minLng <= lng && lng <= b.maxLng
+	}
+	return lng >= b.minLng || lng <= b.maxLng
+}
+
+// circleBox - returns the bounding box of the circle.
+func circleBox(lat, lng, radius float64) geoBox {
+	deg := 180 / math.Pi
+	dLat := radius / earthRadius * deg
+	if lat-dLat <= -90 || lat+dLat >= 90 {
+		// the circle contains a pole
+		return geoBox{math.Max(lat-dLat, -90), -180, math.Min(lat+dLat, 90), 180}
+	}
+
+	dLng := math.Asin(math.Sin(radius/earthRadius)/math.Cos(lat/deg)) * deg
+	if math.IsNaN(dLng) || dLng >= 180 {
+		return geoBox{lat - dLat, -180, lat + dLat, 180}
+	}
+
+	minLng, maxLng := lng-dLng, lng+dLng
+	if minLng < -180 {
+		minLng += 360
+	}
+	if maxLng > 180 {
+		maxLng -= 360
+	}
+	return geoBox{lat - dLat, minLng, lat + dLat, maxLng}
+}
+
+// geoBoxCells - returns the geohashes of the fewest cells covering the box with precision maxPrecision or less.
+// It returns nil if the box needs more cells than MaxFilterAlternatives even with precision 1.
+func geoBoxCells(box geoBox, maxPrecision int) []string {
+	for precision := clampGeohashPrecision(maxPrecision); precision >= 1; precision-- {
+		latBits, lngBits := geohashBits(precision)
+		latLo, latHi := geohashIndex(box.minLat, -90, 180, latBits), geohashIndex(box.maxLat, -90, 180, latBits)
+		lngLo, lngHi := geohashIndex(box.minLng, -180, 360, lngBits), geohashIndex(box.maxLng, -180, 360, lngBits)
+
+		lngCells := uint64(1) << lngBits
+		cols := lngHi - lngLo + 1
+		if box.minLng > box.maxLng {
+			// crossing the antimeridian
+			cols = lngCells - lngLo + lngHi + 1
+		}
+		if cols > lngCells {
+			cols = lngCells
+		}
+		if rows := latHi - latLo + 1; rows*cols > MaxFilterAlternatives {
+			continue
+		}
+
+		cells := make([]string, 0, MaxFilterAlternatives)
+		for latIdx := latLo; latIdx <= latHi; latIdx++ {
+			for i := uint64(0); i < cols; i++ {
+				cells = append(cells, geohashCell(latIdx, (lngLo+i)%lngCells, precision))
+			}
+		}
+		return cells
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package xim
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGeohash(t *testing.T) {
+	for _, c := range []struct {
+		lat, lng  float64
+		precision int
+		expected  string
+	}{
+		{57.64911, 10.40744, 11, "u4pruydqqvj"},
+		{35.681236, 139.767125, 9, "xn76urx66"},
+		{-90, -180, 3, "000"},
+		{90, 180, 3, "zzz"},
+		{35.681236, 139.767125, 0, "x"},
+		{35.681236, 139.767125, 20, "xn76urx6606p"},
+	} {
+		if actual := Geohash(c.lat, c.lng, c.precision); actual != c.expected {
+			t.Errorf("%v: expected: %s, but was: %s", c, c.expected, actual)
+		}
+	}
+}
+
+func TestGeoTokens(t *testing.T) {
+	actual := GeoTokens(35.681236, 139.767125, 4)
+	expected := []string{"x", "xn", "xn7", "xn76"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+	}
+}
+
+func TestGeoDistance(t *testing.T) {
+	// Tokyo station to Osaka station
+	if actual := GeoDistance(35.681236, 139.767125, 34.702485, 135.495951); math.Abs(actual-403000) > 1000 {
+		t.Errorf("expected: about 403km, but was: %f", actual)
+	}
+	if actual := GeoDistance(0, 179.9, 0, -179.9); math.Abs(actual-22239) > 10 {
+		t.Errorf("expected: about 22.2km across the antimeridian, but was: %f", actual)
+	}
+}
+
+func TestGeoBoxCells(t *testing.T) {
+	t.Run("cells cover the box", func(t *testing.T) {
+		box := circleBox(35.681236, 139.767125, 1000)
+		cells := geoBoxCells(box, 9)
+		if len(cells) == 0 || len(cells) > MaxFilterAlternatives {
+			t.Fatalf("unexpected number of cells: %d", len(cells))
+		}
+		corners := [][2]float64{
+			{box.minLat, box.minLng}, {box.minLat, box.maxLng}, {box.maxLat, box.minLng}, {box.maxLat, box.maxLng},
+		}
+		for _, corner := range corners {
+			hash := Geohash(corner[0], corner[1], len(cells[0]))
+			found := false
+			for _, c := range cells {
+				found = found || c == hash
+			}
+			if !found {
+				t.Errorf("corner %v: %s is not covered by %v", corner, hash, cells)
+			}
+		}
+	})
+
+	t.Run("crossing the antimeridian", func(t *testing.T) {
+		cells := geoBoxCells(circleBox(0, 179.99, 5000), 6)
+		west, east := false, false
+		for _, c := range cells {
+			west = west || strings.HasPrefix(c, "8")
+			east = east || strings.HasPrefix(c, "x")
+		}
+		if !west || !east {
+			t.Errorf("expected: cells of both sides, but was: %v", cells)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		if actual := geoBoxCells(geoBox{-90, -180, 90, 180}, 5); actual != nil {
+			t.Errorf("unexpected, actual: `%v`, expected: nil", actual)
+		}
+	})
+}
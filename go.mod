@@ -2,4 +2,7 @@ module github.com/go-utils/xim
 
 go 1.12
 
-require golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1
+require (
+	golang.org/x/text v0.3.8
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1
+)
@@ -0,0 +1,359 @@
+package xim
+
+import (
+	"strings"
+	"unicode"
+)
+
+// graphemeProperty - Grapheme_Cluster_Break property of UAX #29 with some simplification.
+type graphemeProperty uint8
+
+const (
+	gpOther graphemeProperty = iota
+	gpCR
+	gpLF
+	gpControl
+	gpExtend
+	gpZWJ
+	gpRegionalIndicator
+	gpPrepend
+	gpSpacingMark
+	gpL
+	gpV
+	gpT
+	gpLV
+	gpLVT
+)
+
+var prependTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0600, Hi: 0x0605, Stride: 1},
+		{Lo: 0x06dd, Hi: 0x06dd, Stride: 1},
+		{Lo: 0x070f, Hi: 0x070f, Stride: 1},
+		{Lo: 0x0890, Hi: 0x0891, Stride: 1},
+		{Lo: 0x08e2, Hi: 0x08e2, Stride: 1},
+		{Lo: 0x0d4e, Hi: 0x0d4e, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x110bd, Hi: 0x110bd, Stride: 1},
+		{Lo: 0x110cd, Hi: 0x110cd, Stride: 1},
+	},
+}
+
+// emoji modifiers, tags and variation selectors are handled as Extend.
+var extendTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x200c, Hi: 0x200c, Stride: 1},
+		{Lo: 0xff9e, Hi: 0xff9f, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f3fb, Hi: 0x1f3ff, Stride: 1},
+		{Lo: 0xe0020, Hi: 0xe007f, Stride: 1},
+	},
+}
+
+// Extended_Pictographic, approximately.
+var extendedPictographicTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00a9, Hi: 0x00a9, Stride: 1},
+		{Lo: 0x00ae, Hi: 0x00ae, Stride: 1},
+		{Lo: 0x203c, Hi: 0x203c, Stride: 1},
+		{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+		{Lo: 0x2122, Hi: 0x2122, Stride: 1},
+		{Lo: 0x2139, Hi: 0x2139, Stride: 1},
+		{Lo: 0x2194, Hi: 0x2199, Stride: 1},
+		{Lo: 0x21a9, Hi: 0x21aa, Stride: 1},
+		{Lo: 0x231a, Hi: 0x231b, Stride: 1},
+		{Lo: 0x2328, Hi: 0x2328, Stride: 1},
+		{Lo: 0x2388, Hi: 0x2388, Stride: 1},
+		{Lo: 0x23cf, Hi: 0x23cf, Stride: 1},
+		{Lo: 0x23e9, Hi: 0x23f3, Stride: 1},
+		{Lo: 0x23f8, Hi: 0x23fa, Stride: 1},
+		{Lo: 0x24c2, Hi: 0x24c2, Stride: 1},
+		{Lo: 0x25aa, Hi: 0x25ab, Stride: 1},
+		{Lo: 0x25b6, Hi: 0x25b6, Stride: 1},
+		{Lo: 0x25c0, Hi: 0x25c0, Stride: 1},
+		{Lo: 0x25fb, Hi: 0x25fe, Stride: 1},
+		{Lo: 0x2600, Hi: 0x27bf, Stride: 1},
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2b05, Hi: 0x2b07, Stride: 1},
+		{Lo: 0x2b1b, Hi: 0x2b1c, Stride: 1},
+		{Lo: 0x2b50, Hi: 0x2b50, Stride: 1},
+		{Lo: 0x2b55, Hi: 0x2b55, Stride: 1},
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+		{Lo: 0x303d, Hi: 0x303d, Stride: 1},
+		{Lo: 0x3297, Hi: 0x3297, Stride: 1},
+		{Lo: 0x3299, Hi: 0x3299, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1f1e5, Stride: 1},
+		{Lo: 0x1f200, Hi: 0x1f3fa, Stride: 1},
+		{Lo: 0x1f400, Hi: 0x1faff, Stride: 1},
+		{Lo: 0x1fc00, Hi: 0x1fffd, Stride: 1},
+	},
+}
+
+// viramas which link Indic consonants into a conjunct (Indic_Conjunct_Break=Linker).
+var indicLinkerTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x094d, Hi: 0x094d, Stride: 1}, // Devanagari
+		{Lo: 0x09cd, Hi: 0x09cd, Stride: 1}, // Bengali
+		{Lo: 0x0acd, Hi: 0x0acd, Stride: 1}, // Gujarati
+		{Lo: 0x0b4d, Hi: 0x0b4d, Stride: 1}, // Oriya
+		{Lo: 0x0c4d, Hi: 0x0c4d, Stride: 1}, // Telugu
+		{Lo: 0x0d4d, Hi: 0x0d4d, Stride: 1}, // Malayalam
+	},
+}
+
+// Indic consonants which can be linked into a conjunct (Indic_Conjunct_Break=Consonant).
+var indicConsonantTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0915, Hi: 0x0939, Stride: 1},
+		{Lo: 0x0958, Hi: 0x095f, Stride: 1},
+		{Lo: 0x0978, Hi: 0x097f, Stride: 1},
+		{Lo: 0x0995, Hi: 0x09a8, Stride: 1},
+		{Lo: 0x09aa, Hi: 0x09b0, Stride: 1},
+		{Lo: 0x09b2, Hi: 0x09b2, Stride: 1},
+		{Lo: 0x09b6, Hi: 0x09b9, Stride: 1},
+		{Lo: 0x09dc, Hi: 0x09dd, Stride: 1},
+		{Lo: 0x09df, Hi: 0x09df, Stride: 1},
+		{Lo: 0x09f0, Hi: 0x09f1, Stride: 1},
+		{Lo: 0x0a95, Hi: 0x0aa8, Stride: 1},
+		{Lo: 0x0aaa, Hi: 0x0ab0, Stride: 1},
+		{Lo: 0x0ab2, Hi: 0x0ab3, Stride: 1},
+		{Lo: 0x0ab5, Hi: 0x0ab9, Stride: 1},
+		{Lo: 0x0af9, Hi: 0x0af9, Stride: 1},
+		{Lo: 0x0b15, Hi: 0x0b28, Stride: 1},
+		{Lo: 0x0b2a, Hi: 0x0b30, Stride: 1},
+		{Lo: 0x0b32, Hi: 0x0b33, Stride: 1},
+		{Lo: 0x0b35, Hi: 0x0b39, Stride: 1},
+		{Lo: 0x0b5c, Hi: 0x0b5d, Stride: 1},
+		{Lo: 0x0b5f, Hi: 0x0b5f, Stride: 1},
+		{Lo: 0x0b71, Hi: 0x0b71, Stride: 1},
+		{Lo: 0x0c15, Hi: 0x0c28, Stride: 1},
+		{Lo: 0x0c2a, Hi: 0x0c39, Stride: 1},
+		{Lo: 0x0c58, Hi: 0x0c5a, Stride: 1},
+		{Lo: 0x0d15, Hi: 0x0d3a, Stride: 1},
+	},
+}
+
+func graphemePropertyOf(r rune) graphemeProperty {
+	switch {
+	case r == '\r':
+		return gpCR
+	case r == '\n':
+		return gpLF
+	case r == '\u200d':
+		return gpZWJ
+	case 0x1f1e6 <= r && r <= 0x1f1ff:
+		return gpRegionalIndicator
+	case unicode.In(r, extendTable, unicode.Mn, unicode.Me):
+		return gpExtend
+	case unicode.In(r, prependTable):
+		return gpPrepend
+	case unicode.In(r, unicode.Cc, unicode.Zl, unicode.Zp, unicode.Cf):
+		return gpControl
+	case unicode.Is(unicode.Mc, r):
+		return gpSpacingMark
+	case 0x1100 <= r && r <= 0x115f, 0xa960 <= r && r <= 0xa97c:
+		return gpL
+	case 0x1160 <= r && r <= 0x11a7, 0xd7b0 <= r && r <= 0xd7c6:
+		return gpV
+	case 0x11a8 <= r && r <= 0x11ff, 0xd7cb <= r && r <= 0xd7fb:
+		return gpT
+	case 0xac00 <= r && r <= 0xd7a3:
+		if (r-0xac00)%28 == 0 {
+			return gpLV
+		}
+		return gpLVT
+	}
+	return gpOther
+}
+
+// graphemeState - context of the current cluster to decide a boundary.
+type graphemeState struct {
+	prev       graphemeProperty
+	pictograph bool // GB11: the cluster is ExtPict Extend*
+	pictoZWJ   bool // GB11: the cluster is ExtPict Extend* ZWJ
+	riCount    int  // GB12, GB13: number of preceding regional indicators
+	consonant  bool // GB9c: the cluster is Consonant [Extend Linker]*
+	linked     bool // GB9c: the cluster is Consonant [Extend Linker]* Linker [Extend Linker]*
+}
+
+// isBoundary - reports whether a cluster boundary exists before r.
+func (st *graphemeState) isBoundary(r rune, p graphemeProperty) bool {
+	prev := st.prev
+	switch {
+	case prev == gpCR && p == gpLF: // GB3
+		return false
+	case prev == gpCR, prev == gpLF, prev == gpControl: // GB4
+		return true
+	case p == gpCR, p == gpLF, p == gpControl: // GB5
+		return true
+	case prev == gpL && (p == gpL || p == gpV || p == gpLV || p == gpLVT): // GB6
+		return false
+	case (prev == gpLV || prev == gpV) && (p == gpV || p == gpT): // GB7
+		return false
+	case (prev == gpLVT || prev == gpT) && p == gpT: // GB8
+		return false
+	case p == gpExtend, p == gpZWJ: // GB9
+		return false
+	case p == gpSpacingMark: // GB9a
+		return false
+	case prev == gpPrepend: // GB9b
+		return false
+	case st.linked && unicode.Is(indicConsonantTable, r): // GB9c
+		return false
+	case st.pictoZWJ && unicode.Is(extendedPictographicTable, r): // GB11
+		return false
+	case p == gpRegionalIndicator && st.riCount%2 == 1: // GB12, GB13
+		return false
+	}
+	return true // GB999
+}
+
+// next - updates the state with r.
+func (st *graphemeState) next(r rune, p graphemeProperty, boundary bool) {
+	if boundary {
+		st.pictograph = unicode.Is(extendedPictographicTable, r)
+		st.pictoZWJ = false
+		st.consonant = unicode.Is(indicConsonantTable, r)
+		st.linked = false
+	} else {
+		switch {
+		case st.pictoZWJ && unicode.Is(extendedPictographicTable, r):
+			st.pictoZWJ = false
+		case st.pictograph && !st.pictoZWJ && p == gpZWJ:
+			st.pictoZWJ = true
+		case st.pictograph && !st.pictoZWJ && p == gpExtend:
+		default:
+			st.pictograph = false
+			st.pictoZWJ = false
+		}
+
+		switch {
+		case (st.consonant || st.linked) && unicode.Is(indicLinkerTable, r):
+			st.linked = true
+		case st.linked && unicode.Is(indicConsonantTable, r):
+			st.consonant = true
+			st.linked = false
+		case (st.consonant || st.linked) && (p == gpExtend || p == gpZWJ):
+		default:
+			st.consonant = false
+			st.linked = false
+		}
+	}
+
+	if p == gpRegionalIndicator {
+		st.riCount++
+	} else {
+		st.riCount = 0
+	}
+	st.prev = p
+}
+
+// Graphemes - splits s into extended grapheme clusters, user-perceived characters.
+// e.g. "👨‍👩‍👧", "👍🏽", "🇯🇵", "é" (e + U+0301) and "क्षि" are single clusters respectively.
+func Graphemes(s string) []string {
+	clusters := make([]string, 0, len(s))
+
+	var st graphemeState
+	start := 0
+	for i, r := range s {
+		p := graphemePropertyOf(r)
+		boundary := i == 0 || st.isBoundary(r, p)
+		if boundary && i > start {
+			clusters = append(clusters, s[start:i])
+			start = i
+		}
+		st.next(r, p, boundary)
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+
+	return clusters
+}
+
+// graphemeWords - splits s into words of grapheme clusters.
+func graphemeWords(s string, isSeparator func(rune) bool) [][]string {
+	words := make([][]string, 0, 8)
+	for _, w := range strings.FieldsFunc(s, isSeparator) {
+		words = append(words, Graphemes(w))
+	}
+	return words
+}
+
+// GraphemeNgrams - returns n-gram tokens of grapheme clusters from s.
+func GraphemeNgrams(s string, n int) []string {
+	return graphemeNgrams(s, n, isDefaultSeparator)
+}
+
+func graphemeNgrams(s string, n int, isSeparator func(rune) bool) []string {
+	tokenMap := make(map[string]struct{})
+	if n > 0 {
+		for _, w := range graphemeWords(s, isSeparator) {
+			for i := 0; i+n <= len(w); i++ {
+				tokenMap[strings.Join(w[i:i+n], "")] = struct{}{}
+			}
+		}
+	}
+	return tokenSlice(tokenMap)
+}
+
+// GraphemeBigrams - returns bigram tokens of grapheme clusters from s.
+func GraphemeBigrams(s string) []string {
+	return graphemeNgrams(s, 2, isDefaultSeparator)
+}
+
+// GraphemeBiunigrams - returns bigram and unigram tokens of grapheme clusters from s.
+func GraphemeBiunigrams(s string) []string {
+	return append(graphemeNgrams(s, 2, isDefaultSeparator), graphemeNgrams(s, 1, isDefaultSeparator)...)
+}
+
+// GraphemePrefixes - returns prefix tokens of grapheme clusters from s.
+func GraphemePrefixes(s string) []string {
+	return graphemeTokenize(s, false, isDefaultSeparator, LengthBounds{})
+}
+
+// GraphemeSuffixes - returns suffix tokens of grapheme clusters from s.
+func GraphemeSuffixes(s string) []string {
+	return graphemeTokenize(s, true, isDefaultSeparator, LengthBounds{})
+}
+
+func graphemeTokenize(s string, isSuffix bool, isSeparator func(rune) bool, bounds LengthBounds) []string {
+	tokenMap := make(map[string]struct{})
+	for _, w := range graphemeWords(s, isSeparator) {
+		if len(w) < bounds.Min {
+			tokenMap[strings.Join(w, "")] = struct{}{}
+			continue
+		}
+		for i := bounds.Min; i <= len(w) && !bounds.exceeds(i); i++ {
+			if i == 0 {
+				continue
+			}
+			if isSuffix {
+				tokenMap[strings.Join(w[len(w)-i:], "")] = struct{}{}
+			} else {
+				tokenMap[strings.Join(w[:i], "")] = struct{}{}
+			}
+		}
+	}
+	return tokenSlice(tokenMap)
+}
+
+func graphemeLen(s string) int {
+	return len(Graphemes(s))
+}
+
+// graphemeTruncate - returns the first or last n grapheme clusters of s.
+func graphemeTruncate(s string, n int, fromEnd bool) string {
+	clusters := Graphemes(s)
+	if len(clusters) <= n {
+		return s
+	}
+	if fromEnd {
+		return strings.Join(clusters[len(clusters)-n:], "")
+	}
+	return strings.Join(clusters[:n], "")
+}
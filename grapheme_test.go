@@ -0,0 +1,116 @@
+package xim
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGraphemes(t *testing.T) {
+	cases := []struct {
+		name     string
+		s        string
+		expected []string
+	}{
+		{"ascii", "abc", []string{"a", "b", "c"}},
+		{"japanese", "がぎ", []string{"が", "ぎ"}},
+		{"combining accent", "café", []string{"c", "a", "f", "é"}},
+		{"CRLF", "a\r\nb", []string{"a", "\r\n", "b"}},
+		{"ZWJ family", "a👨‍👩‍👧b", []string{"a", "👨‍👩‍👧", "b"}},
+		{"skin tone", "👍🏽👍", []string{"👍🏽", "👍"}},
+		{"flags", "🇯🇵🇺🇸🇫", []string{"🇯🇵", "🇺🇸", "🇫"}},
+		{"keycap", "#️⃣1", []string{"#️⃣", "1"}},
+		{"variation selector", "❤️a", []string{"❤️", "a"}},
+		{"rainbow flag", "🏳️‍🌈", []string{"🏳️‍🌈"}},
+		{"tag sequence", "🏴󠁧󠁢󠁳󠁣󠁴󠁿", []string{"🏴󠁧󠁢󠁳󠁣󠁴󠁿"}},
+		{"ZWJ without pictograph", "a‍b", []string{"a‍", "b"}},
+		{"hangul jamo", "각가", []string{"각", "가"}},
+		{"devanagari", "नमस्ते", []string{"न", "म", "स्ते"}},
+		{"devanagari conjunct", "क्षि", []string{"क्षि"}},
+		{"bengali", "বাংলা", []string{"বাং", "লা"}},
+		{"telugu", "తెలుగు", []string{"తె", "లు", "గు"}},
+		{"halfwidth katakana", "ｶﾞｷ", []string{"ｶﾞ", "ｷ"}},
+		{"empty", "", []string{}},
+	}
+
+	for _, c := range cases {
+		c := c // escape: Using the variable on range scope `c` in loop literal
+		t.Run(c.name, func(t *testing.T) {
+			if actual := Graphemes(c.s); !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("unexpected, actual: `%q`, expected: `%q`", actual, c.expected)
+			}
+		})
+	}
+}
+
+func TestGraphemeBigrams(t *testing.T) {
+	result := GraphemeBigrams("👨‍👩‍👧👍🏽 née 🇯🇵")
+	sort.Strings(result)
+
+	expected := []string{"ée", "né", "👨‍👩‍👧👍🏽"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected, actual: `%q`, expected: `%q`", result, expected)
+	}
+}
+
+func TestGraphemeBiunigrams(t *testing.T) {
+	result := GraphemeBiunigrams("👍🏽🇯🇵 क्षि")
+	sort.Strings(result)
+
+	expected := []string{"क्षि", "👍🏽", "👍🏽🇯🇵", "🇯🇵"}
+	sort.Strings(expected)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected, actual: `%q`, expected: `%q`", result, expected)
+	}
+}
+
+func TestGraphemePrefixes(t *testing.T) {
+	result := GraphemePrefixes("👨‍👩‍👧👍🏽 नमस्ते")
+	sort.Strings(result)
+
+	expected := []string{"न", "नम", "नमस्ते", "👨‍👩‍👧", "👨‍👩‍👧👍🏽"}
+	sort.Strings(expected)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected, actual: `%q`, expected: `%q`", result, expected)
+	}
+}
+
+func TestGraphemeSuffixes(t *testing.T) {
+	result := GraphemeSuffixes("👨‍👩‍👧👍🏽 नमस्ते")
+	sort.Strings(result)
+
+	expected := []string{"स्ते", "मस्ते", "नमस्ते", "👍🏽", "👨‍👩‍👧👍🏽"}
+	sort.Strings(expected)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected, actual: `%q`, expected: `%q`", result, expected)
+	}
+}
+
+func TestGraphemesIndexAndFilter(t *testing.T) {
+	conf := &Config{Graphemes: true}
+
+	idx := NewIndexes(conf)
+	idx.AddBiunigrams("label1", "I ❤️ 👨‍👩‍👧 नमस्ते")
+	builtIndexes := idx.MustBuild()
+
+	for _, query := range []string{"👨‍👩‍👧", "मस्ते", "❤️"} {
+		filter := NewFilters(conf)
+		filter.AddBiunigrams("label1", query)
+		builtFilters := filter.MustBuild()
+
+		for builtFilter := range builtFilters {
+			if !contains(t, builtIndexes, builtFilter) {
+				t.Errorf("filter: %s not contains", builtFilter)
+			}
+		}
+	}
+
+	// a half of ZWJ sequence doesn't match with graphemes
+	filter := NewFilters(conf)
+	filter.AddBiunigrams("label1", "👨")
+	for builtFilter := range filter.MustBuild() {
+		if contains(t, builtIndexes, builtFilter) {
+			t.Errorf("filter: %s contains", builtFilter)
+		}
+	}
+}
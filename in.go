@@ -1,50 +1,193 @@
 package xim
 
 import (
+	"encoding/json"
 	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	MaxInBits = 64 // maximum number of bits of InBuilder.
+
+	inGroupBits = 4 // number of bits of a group, which bounds indexes of a bit by 2^(inGroupBits-1)
 )
 
 // Bit - describes In-Filter mask bit
-type Bit uint16
+type Bit uint64
 
 // InBuilder - creates Bit for In-Filter
+// Bits are divided into groups of 4 bits, and indexes are combinations of bits in the same group.
+// So each bit has at most 8 indexes, and a value of any bits has at most 240 indexes.
 type InBuilder struct {
-	nextBit Bit
+	size  int            // number of bits created
+	names map[string]int // bit positions of registered values
 }
 
 // NewInBuilder - creates InBuilder
 func NewInBuilder() *InBuilder {
-	return &InBuilder{nextBit: 1}
+	return &InBuilder{names: make(map[string]int)}
 }
 
 // NewBit - returns a new bit shifted.
+// It panics if MaxInBits bits have been created.
 func (f *InBuilder) NewBit() Bit {
-	if f.nextBit == 0 {
+	if f.size >= MaxInBits {
 		panic("overflow")
 	}
 
-	bit := f.nextBit
-	f.nextBit <<= 1
+	bit := Bit(1) << uint(f.size)
+	f.size++
+
+	return bit
+}
+
+// RegisterValue - returns the bit of the value registered with the name, creating a new bit for a new name.
+// Bits of names are kept by the table exported as JSON, so they don't depend on the order of registration.
+// It panics if MaxInBits bits have been created.
+func (f *InBuilder) RegisterValue(name string) Bit {
+	if pos, ok := f.names[name]; ok {
+		return Bit(1) << uint(pos)
+	}
 
+	bit := f.NewBit()
+	if f.names == nil {
+		f.names = make(map[string]int)
+	}
+	f.names[name] = f.size - 1
 	return bit
 }
 
+// LookupValue - returns the bit of the value registered with the name.
+func (f *InBuilder) LookupValue(name string) (Bit, bool) {
+	pos, ok := f.names[name]
+	if !ok {
+		return 0, false
+	}
+	return Bit(1) << uint(pos), true
+}
+
+// MarshalJSON - exports the table of registered names to bit positions, e.g. {"published":1,"unpublished":0}.
+func (f *InBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.names)
+}
+
+// UnmarshalJSON - imports the table exported by MarshalJSON.
+// Values registered before are validated with the table, and the builder is unchanged if it returns an error.
+// It fails if a name is registered with another bit, a bit is used by another name or created by NewBit,
+// or a bit is out of MaxInBits. Bits created after importing never reuse bits in the table.
+func (f *InBuilder) UnmarshalJSON(data []byte) error {
+	var table map[string]int
+	if err := json.Unmarshal(data, &table); err != nil {
+		return xerrors.Errorf("failed to unmarshal InBuilder table: %w", err)
+	}
+
+	owners := make(map[int]string, len(f.names)+len(table))
+	for name, pos := range f.names {
+		owners[pos] = name
+	}
+	size := f.size
+	for name, pos := range table {
+		if pos < 0 || pos >= MaxInBits {
+			return xerrors.Errorf("bit of %q is out of range: %d", name, pos)
+		}
+		if registered, ok := f.names[name]; ok && registered != pos {
+			return xerrors.Errorf("%q is registered with bit %d, but the table has %d", name, registered, pos)
+		}
+		if owner, ok := owners[pos]; ok && owner != name {
+			return xerrors.Errorf("bit %d of %q is used by %q", pos, name, owner)
+		}
+		if _, ok := owners[pos]; !ok && pos < f.size {
+			return xerrors.Errorf("bit %d of %q is created by NewBit", pos, name)
+		}
+		owners[pos] = name
+		if pos >= size {
+			size = pos + 1
+		}
+	}
+
+	if f.names == nil {
+		f.names = make(map[string]int, len(table))
+	}
+	for name, pos := range table {
+		f.names[name] = pos
+	}
+	f.size = size
+	return nil
+}
+
+// groupMask - returns all the bits created in the group, shifted to the lowest bits.
+func (f *InBuilder) groupMask(group int) Bit {
+	n := f.size - group*inGroupBits
+	switch {
+	case n <= 0:
+		return 0
+	case n > inGroupBits:
+		n = inGroupBits
+	}
+	return 1<<uint(n) - 1
+}
+
+// inToken - returns the index of the combination of bits in the group.
+// Tokens of the first group have no group prefix to be compatible with builders of 4 bits or less.
+func inToken(group int, bits Bit) string {
+	if group == 0 {
+		return fmt.Sprintf("%x", bits)
+	}
+	return fmt.Sprintf("%d:%x", group, bits)
+}
+
 // Indexes - creates indexes for In-Filter with multi-bits
 func (f *InBuilder) Indexes(bits ...Bit) []string {
 	allBits := f.combineBits(bits...)
 
 	indexes := make([]string, 0)
-	for i := Bit(1); i <= f.nextBit-1; i++ {
-		if i&allBits != 0 {
-			indexes = append(indexes, fmt.Sprintf("%x", i))
+	for g := 0; g*inGroupBits < f.size; g++ {
+		groupBits := allBits >> uint(g*inGroupBits) & (1<<inGroupBits - 1)
+		if groupBits == 0 {
+			continue
+		}
+		for i := Bit(1); i <= f.groupMask(g); i++ {
+			if i&groupBits != 0 {
+				indexes = append(indexes, inToken(g, i))
+			}
 		}
 	}
 
 	return indexes
 }
 
-// Indexes - creates indexes for In-Filter
+// Filter - creates indexes for In-Filter
+// It panics if the bits belong to multiple groups of 4 bits, which can't be searched with a single filter.
+//
+// Deprecated: Use Filters.AddIn or InBuilder.Filters, which support bits of any groups.
 func (f *InBuilder) Filter(bits ...Bit) string {
+	filters := f.Filters(bits...)
+	if len(filters) > 1 {
+		panic("bits of multiple groups")
+	}
+	return filters[0]
+}
+
+// Filters - creates alternative filters for In-Filter, one for each group of 4 bits.
+// Values with any of the bits match at least one of them.
+func (f *InBuilder) Filters(bits ...Bit) []string {
+	allBits := f.combineBits(bits...)
+	if allBits == 0 {
+		return []string{inToken(0, 0)}
+	}
+
+	filters := make([]string, 0, 1)
+	for g := 0; g < MaxInBits/inGroupBits; g++ {
+		if groupBits := allBits >> uint(g*inGroupBits) & (1<<inGroupBits - 1); groupBits != 0 {
+			filters = append(filters, inToken(g, groupBits))
+		}
+	}
+	return filters
+}
+
+// mask - returns the hexadecimal mask of bits, which is verified by Matcher.
+func (f *InBuilder) mask(bits ...Bit) string {
 	return fmt.Sprintf("%x", f.combineBits(bits...))
 }
 
@@ -1,6 +1,7 @@
 package xim
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"testing"
@@ -35,7 +36,7 @@ func TestInBuilderBit(t *testing.T) {
 		})
 	}
 
-	uintSize := 16
+	uintSize := MaxInBits
 
 	for i := 9; i < uintSize-1; i++ {
 		assertBit(t, t.Name(), inBuilder.NewBit(), Bit(1<<uint(i)))
@@ -132,3 +133,135 @@ func TestInBuilderFilters(t *testing.T) {
 		t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", t.Name(), filter, expected)
 	}
 }
+
+func TestInBuilderGroups(t *testing.T) {
+	inBuilder := NewInBuilder()
+
+	bits := make([]Bit, 0, 18)
+	for i := 0; i < 18; i++ {
+		bits = append(bits, inBuilder.NewBit())
+	}
+
+	t.Run("indexes", func(t *testing.T) {
+		idxs := inBuilder.Indexes(bits[9])
+		if len(idxs) != 1<<(inGroupBits-1) {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", t.Name(), len(idxs), 1<<(inGroupBits-1))
+		}
+		expected := []string{"2:2", "2:3", "2:6", "2:7", "2:a", "2:b", "2:e", "2:f"}
+		if !reflect.DeepEqual(idxs, expected) {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", t.Name(), idxs, expected)
+		}
+
+		// the last group has 2 bits
+		idxs = inBuilder.Indexes(bits[0], bits[17])
+		expected = []string{"1", "3", "5", "7", "9", "b", "d", "f", "4:2", "4:3"}
+		if !reflect.DeepEqual(idxs, expected) {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", t.Name(), idxs, expected)
+		}
+
+		// values of all bits have every combination of each group
+		if idxs := inBuilder.Indexes(bits...); len(idxs) != 4*15+3 {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", t.Name(), len(idxs), 4*15+3)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		filters := inBuilder.Filters(bits[0], bits[2], bits[9], bits[17])
+		expected := []string{"5", "2:2", "4:2"}
+		if !reflect.DeepEqual(filters, expected) {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", t.Name(), filters, expected)
+		}
+	})
+
+	t.Run("filter of a group", func(t *testing.T) {
+		if filter := inBuilder.Filter(bits[8], bits[9]); filter != "2:3" {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", t.Name(), filter, "2:3")
+		}
+	})
+
+	t.Run("filter of multiple groups", func(t *testing.T) {
+		defer func() {
+			if rec := recover(); rec == nil {
+				t.Errorf("%s: panic expected", t.Name())
+			}
+		}()
+
+		inBuilder.Filter(bits[0], bits[9])
+	})
+}
+
+func TestInBuilderRegisterValue(t *testing.T) {
+	inBuilder := NewInBuilder()
+	unpublished := inBuilder.RegisterValue("unpublished")
+	published := inBuilder.RegisterValue("published")
+
+	assertBit(t, "unpublished", unpublished, 1)
+	assertBit(t, "published", published, 2)
+	assertBit(t, "registered", inBuilder.RegisterValue("unpublished"), unpublished)
+
+	if bit, ok := inBuilder.LookupValue("published"); !ok || bit != published {
+		t.Errorf("%s: unexpected, actual: `%v`, %v, expected: `%v`", t.Name(), bit, ok, published)
+	}
+	if _, ok := inBuilder.LookupValue("discontinued"); ok {
+		t.Errorf("%s: unexpected registered value", t.Name())
+	}
+
+	table, err := json.Marshal(inBuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"published":1,"unpublished":0}`; string(table) != expected {
+		t.Errorf("%s: unexpected, actual: `%s`, expected: `%s`", t.Name(), table, expected)
+	}
+}
+
+func TestInBuilderUnmarshalJSON(t *testing.T) {
+	table := []byte(`{"published":1,"unpublished":0,"discontinued":3}`)
+
+	t.Run("registered in another order", func(t *testing.T) {
+		inBuilder := NewInBuilder()
+		if err := json.Unmarshal(table, inBuilder); err != nil {
+			t.Fatal(err)
+		}
+
+		assertBit(t, "discontinued", inBuilder.RegisterValue("discontinued"), 8)
+		assertBit(t, "published", inBuilder.RegisterValue("published"), 2)
+		assertBit(t, "unpublished", inBuilder.RegisterValue("unpublished"), 1)
+		// bits in the table are never reused
+		assertBit(t, "new", inBuilder.RegisterValue("reserved"), 16)
+	})
+
+	t.Run("consistent with registered values", func(t *testing.T) {
+		inBuilder := NewInBuilder()
+		inBuilder.RegisterValue("unpublished")
+		if err := json.Unmarshal(table, inBuilder); err != nil {
+			t.Errorf("%s: unexpected error: %v", t.Name(), err)
+		}
+	})
+
+	for _, c := range []struct {
+		name  string
+		setup func(inBuilder *InBuilder)
+		table string
+	}{
+		{"registered with another bit", func(inBuilder *InBuilder) { inBuilder.RegisterValue("published") }, string(table)},
+		{"bit used by another name", func(inBuilder *InBuilder) { inBuilder.RegisterValue("draft") }, string(table)},
+		{"bit created by NewBit", func(inBuilder *InBuilder) { inBuilder.NewBit() }, string(table)},
+		{"duplicate bits", func(*InBuilder) {}, `{"a":0,"b":0}`},
+		{"out of range", func(*InBuilder) {}, `{"a":64}`},
+		{"invalid JSON", func(*InBuilder) {}, `["a"]`},
+	} {
+		c := c // escape: Using the variable on range scope `c` in loop literal
+		t.Run(c.name, func(tr *testing.T) {
+			inBuilder := NewInBuilder()
+			c.setup(inBuilder)
+			before, _ := json.Marshal(inBuilder)
+			if err := json.Unmarshal([]byte(c.table), inBuilder); err == nil {
+				tr.Errorf("%s: expected: err != nil, but was: err = nil", tr.Name())
+			}
+			if after, _ := json.Marshal(inBuilder); string(after) != string(before) {
+				tr.Errorf("%s: unexpected change, actual: `%s`, expected: `%s`", tr.Name(), after, before)
+			}
+		})
+	}
+}
@@ -1,7 +1,8 @@
 package xim
 
 import (
-	"strings"
+	"net"
+	"time"
 
 	"golang.org/x/xerrors"
 )
@@ -25,9 +26,7 @@ func NewIndexes(conf *Config) *Indexes {
 
 func (idxs *Indexes) add(label string, indexes ...string) {
 	for _, idx := range indexes {
-		if idxs.conf.IgnoreCase {
-			idx = strings.ToLower(idx)
-		}
+		idx = idxs.conf.normalize(idx)
 
 		if _, ok := idxs.m[label]; !ok {
 			idxs.m[label] = make(map[string]struct{})
@@ -43,24 +42,114 @@ func (idxs *Indexes) Add(label string, indexes ...string) *Indexes {
 	return idxs
 }
 
+// AddTokenized - adds new indexes tokenized by t with a label.
+func (idxs *Indexes) AddTokenized(label string, t Tokenizer, s string) *Indexes {
+	return idxs.Add(label, t.IndexTokens(idxs.conf.normalize(s))...)
+}
+
 // AddBigrams - adds new bigram indexes with a label.
 func (idxs *Indexes) AddBigrams(label string, s string) *Indexes {
-	return idxs.Add(label, Bigrams(s)...)
+	return idxs.AddTokenized(label, idxs.conf.tokenizers().bigrams, s)
 }
 
 // AddBiunigrams - adds new biunigram indexes with a label.
 func (idxs *Indexes) AddBiunigrams(label string, s string) *Indexes {
-	return idxs.Add(label, Biunigrams(s)...)
+	return idxs.AddTokenized(label, idxs.conf.tokenizers().biunigrams, s)
+}
+
+// AddNgrams - adds new n-gram indexes with a label.
+// All the grams shorter than n are also saved so that Filters.AddNgrams can search queries shorter than n.
+func (idxs *Indexes) AddNgrams(label string, s string, n int) *Indexes {
+	return idxs.AddTokenized(label, idxs.conf.tokenizers().ngrams(n), s)
+}
+
+// AddPartial - adds new partial match indexes with a label.
+// They are n-grams of Config.PartialGrams of the label including shorter grams, i.e. biunigrams by default.
+func (idxs *Indexes) AddPartial(label string, s string) *Indexes {
+	return idxs.AddNgrams(label, s, idxs.conf.partialGrams(label))
 }
 
 // AddPrefixes - adds new prefix indexes with a label.
+// Their lengths are bounded with Config.AffixBounds of the label.
 func (idxs *Indexes) AddPrefixes(label string, s string) *Indexes {
-	return idxs.Add(label, Prefixes(s)...)
+	return idxs.AddTokenized(label, idxs.conf.tokenizers().prefixes(idxs.conf.AffixBounds[label]), s)
 }
 
 // AddSuffixes - adds new prefix indexes with a label.
+// Their lengths are bounded with Config.AffixBounds of the label.
 func (idxs *Indexes) AddSuffixes(label string, s string) *Indexes {
-	return idxs.Add(label, Suffixes(s)...)
+	return idxs.AddTokenized(label, idxs.conf.tokenizers().suffixes(idxs.conf.AffixBounds[label]), s)
+}
+
+// AddPhrasePrefixes - adds new prefix indexes of the whole s with a label.
+// Their lengths are bounded with Config.AffixBounds of the label.
+func (idxs *Indexes) AddPhrasePrefixes(label string, s string) *Indexes {
+	return idxs.AddTokenized(label, idxs.conf.tokenizers().phrases(false, idxs.conf.AffixBounds[label]), s)
+}
+
+// AddPhraseSuffixes - adds new suffix indexes of the whole s with a label.
+// Their lengths are bounded with Config.AffixBounds of the label.
+func (idxs *Indexes) AddPhraseSuffixes(label string, s string) *Indexes {
+	return idxs.AddTokenized(label, idxs.conf.tokenizers().phrases(true, idxs.conf.AffixBounds[label]), s)
+}
+
+// AddShingles - adds new word shingle indexes with a label.
+// All the shingles shorter than size words are also saved so that Filters.AddPhrase can search shorter phrases.
+func (idxs *Indexes) AddShingles(label string, s string, size int) *Indexes {
+	return idxs.AddTokenized(label, idxs.conf.tokenizers().shingles(size), s)
+}
+
+// AddStems - adds new stem indexes of English words with a label.
+func (idxs *Indexes) AddStems(label string, s string) *Indexes {
+	return idxs.AddTokenized(label, idxs.conf.tokenizers().stems, s)
+}
+
+// AddPhonetic - adds new phonetic code indexes of words with a label.
+// Names spelled differently but pronounced alike have the same codes. e.g. "Smith" and "Smyth".
+func (idxs *Indexes) AddPhonetic(label string, s string, p Phonetic) *Indexes {
+	return idxs.AddTokenized(label, idxs.conf.tokenizers().phonetic(p), s)
+}
+
+// AddDeletions - adds new deletion variant indexes of words with a label for typo-tolerant search.
+// distance is 1 or 2, and 2 is applied to words of 5 characters or more only.
+func (idxs *Indexes) AddDeletions(label string, s string, distance int) *Indexes {
+	return idxs.AddTokenized(label, idxs.conf.tokenizers().deletions(distance), s)
+}
+
+// AddNumberRange - adds new bucket indexes of value for range search with a label.
+// Values out of the spec are not indexed. It panics if the spec is invalid.
+func (idxs *Indexes) AddNumberRange(label string, value int64, spec NumberRangeSpec) *Indexes {
+	idxs.add(label, NumberRangeTokens(value, spec)...)
+	return idxs
+}
+
+// AddTimeBuckets - adds new calendar bucket indexes of t for range search with a label.
+// It panics if the spec is invalid.
+func (idxs *Indexes) AddTimeBuckets(label string, t time.Time, spec TimeBucketSpec) *Indexes {
+	idxs.add(label, TimeBucketTokens(t, spec)...)
+	return idxs
+}
+
+// AddInterval - adds new calendar bucket indexes of the interval [start, end) with a label.
+// Long intervals are indexed with coarser buckets than the spec to bound the number of indexes.
+// It panics if the spec is invalid.
+func (idxs *Indexes) AddInterval(label string, start, end time.Time, spec TimeBucketSpec) *Indexes {
+	idxs.add(label, IntervalTokens(start, end, spec)...)
+	return idxs
+}
+
+// AddGeo - adds new geohash prefix indexes of the location from precision 1 to maxPrecision with a label.
+// maxPrecision is clamped into [1, MaxGeohashPrecision].
+func (idxs *Indexes) AddGeo(label string, lat, lng float64, maxPrecision int) *Indexes {
+	idxs.add(label, GeoTokens(lat, lng, maxPrecision)...)
+	return idxs
+}
+
+// AddIP - adds new prefix indexes of the IP address with a label.
+// Prefix lengths are Config.IPPrefixes of the label. Invalid addresses are not indexed.
+func (idxs *Indexes) AddIP(label string, ip net.IP) *Indexes {
+	idxs.add(label, IPTokens(ip, idxs.conf.ipPrefixLengths(label))...)
+	return idxs
 }
 
 // AddSomething - adds new indexes with a label.
@@ -55,6 +55,55 @@ func TestAddBiunigramsIndex(t *testing.T) {
 	assertBuiltIndex(t, built, expected)
 }
 
+func TestAddNgramsIndex(t *testing.T) {
+	idx := NewIndexes(nil)
+	idx.AddNgrams("label1", "abcd dあ", 3)
+
+	expected := make(map[string]bool)
+	for _, s := range []string{"a", "b", "c", "d", "あ", "ab", "bc", "cd", "dあ", "abc", "bcd"} {
+		expected["label1 "+s] = true
+	}
+
+	built := idx.MustBuild()
+	assertBuiltIndex(t, built, expected)
+}
+
+func TestAddPartialIndex(t *testing.T) {
+	idx := NewIndexes(&Config{PartialGrams: map[string]int{"label2": 3}})
+	idx.AddPartial("label1", "abc")
+	idx.AddPartial("label2", "abcd")
+
+	assertBuiltIndex(t, idx.MustBuild(), map[string]bool{
+		"label1 a":   true,
+		"label1 b":   true,
+		"label1 c":   true,
+		"label1 ab":  true,
+		"label1 bc":  true,
+		"label2 a":   true,
+		"label2 b":   true,
+		"label2 c":   true,
+		"label2 d":   true,
+		"label2 ab":  true,
+		"label2 bc":  true,
+		"label2 cd":  true,
+		"label2 abc": true,
+		"label2 bcd": true,
+	})
+}
+
+func TestAddShinglesIndex(t *testing.T) {
+	idx := NewIndexes(nil)
+	idx.AddShingles("label1", "new york pizza", 2)
+
+	assertBuiltIndex(t, idx.MustBuild(), map[string]bool{
+		"label1 new":        true,
+		"label1 york":       true,
+		"label1 pizza":      true,
+		"label1 new york":   true,
+		"label1 york pizza": true,
+	})
+}
+
 func TestAddPrefixesIndex(t *testing.T) {
 	idx := NewIndexes(nil)
 	idx.AddPrefixes("label1", "abc dあいbCh")
@@ -298,6 +347,33 @@ func TestIndexConfigIgnoreCase(t *testing.T) {
 	assertBuiltIndex(t, built, expected)
 }
 
+func TestIndexConfigNormalization(t *testing.T) {
+	t.Run("NormalizationWidth", func(t *testing.T) {
+		idx := NewIndexes(&Config{Normalization: NormalizationWidth})
+		idx.Add("label1", "ＡＢＣ１２３", "ｶﾞｷﾞ")
+		idx.AddBigrams("label2", "ｶﾞｷﾞ")
+
+		assertBuiltIndex(t, idx.MustBuild(), map[string]bool{
+			"label1 ABC123": true,
+			"label1 ガギ":     true,
+			"label2 ガギ":     true,
+		})
+	})
+
+	t.Run("NormalizationNFKC", func(t *testing.T) {
+		idx := NewIndexes(&Config{Normalization: NormalizationNFKC, IgnoreCase: true})
+		idx.Add("label1", "ＡＢＣ①", "㍻")
+		idx.AddPrefixes("label2", "ｶﾞｷﾞ")
+
+		assertBuiltIndex(t, idx.MustBuild(), map[string]bool{
+			"label1 abc1": true,
+			"label1 平成":   true,
+			"label2 ガ":    true,
+			"label2 ガギ":   true,
+		})
+	})
+}
+
 func TestIndexConfigSaveNoFiltersIndex(t *testing.T) {
 	idx := NewIndexes(&Config{SaveNoFiltersIndex: true})
 	idx.Add("label1", "a")
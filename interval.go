@@ -0,0 +1,105 @@
+package xim
+
+import (
+	"math"
+	"time"
+)
+
+// intervalMaxBuckets - maximum number of buckets covering an interval.
+// Longer intervals are covered with coarser buckets.
+const intervalMaxBuckets = 64
+
+// prefixes of interval tokens.
+const (
+	intervalCoverPrefix = "c:" // the interval covers the bucket
+	intervalTouchPrefix = "a:" // the interval covers the bucket or a part of it
+)
+
+// intervalLongToken - the token of intervals which need more buckets than intervalMaxBuckets even by years.
+// Every query searches it, and the intervals are verified by the PostFilter.
+const intervalLongToken = "l:"
+
+// bounds of times in UnixNano.
+var (
+	minUnixNanoTime = time.Unix(0, math.MinInt64)
+	maxUnixNanoTime = time.Unix(0, math.MaxInt64)
+)
+
+// unixNano - returns t in UnixNano, which is saturated if t is out of its range such as the year 9999.
+// So open-ended intervals can be verified, though ranges out of years 1678 to 2262 can't be distinguished.
+func unixNano(t time.Time) int64 {
+	switch {
+	case t.Before(minUnixNanoTime):
+		return math.MinInt64
+	case t.After(maxUnixNanoTime):
+		return math.MaxInt64
+	}
+	return t.UnixNano()
+}
+
+// IntervalTokens - returns calendar bucket tokens of the interval [start, end).
+// They are the fewest buckets covering the interval and all the buckets containing them.
+// The interval is widened to the granularity of the spec, or coarser if it needs more buckets than 64.
+// Intervals needing more buckets than 64 even by years have only a token for long intervals.
+func IntervalTokens(start, end time.Time, spec TimeBucketSpec) []string {
+	spec.mustValidate()
+	if !start.Before(end) {
+		return nil
+	}
+
+	start, end = start.In(spec.location()), end.In(spec.location())
+	var buckets []timeBucket
+	for finest := spec.Granularity; finest >= TimeYear; finest-- {
+		buckets = timeBuckets(start, end, finest)
+		if len(buckets) <= intervalMaxBuckets {
+			break
+		}
+	}
+	if len(buckets) > intervalMaxBuckets {
+		return []string{intervalLongToken}
+	}
+
+	tokenMap := make(map[string]struct{}, len(buckets)*2)
+	for _, b := range buckets {
+		tokenMap[intervalCoverPrefix+b.token()] = struct{}{}
+		for g := TimeYear; g <= b.granularity; g++ {
+			tokenMap[intervalTouchPrefix+b.start.Format(g.layout())] = struct{}{}
+		}
+	}
+	return tokenSlice(tokenMap)
+}
+
+// intervalFilterOptions - returns alternative tokens to search intervals overlapping [start, end).
+// An interval overlaps if it touches a bucket of the range, or covers a bucket containing a bucket of the range,
+// or is a long interval. It returns no options if even buckets by years are more than MaxFilterAlternatives.
+func intervalFilterOptions(start, end time.Time, spec TimeBucketSpec) [][]string {
+	spec.mustValidate()
+	if !start.Before(end) {
+		return [][]string{{rangeNone}}
+	}
+
+	start, end = start.In(spec.location()), end.In(spec.location())
+	var tokens []string
+	for finest := spec.Granularity; finest >= TimeYear; finest-- {
+		tokenMap := make(map[string]struct{}, 32)
+		for _, b := range timeBuckets(start, end, finest) {
+			tokenMap[intervalTouchPrefix+b.token()] = struct{}{}
+			for g := TimeYear; g < b.granularity; g++ {
+				tokenMap[intervalCoverPrefix+b.start.Format(g.layout())] = struct{}{}
+			}
+		}
+		tokens = tokenSlice(tokenMap)
+		if len(tokens) < MaxFilterAlternatives {
+			break
+		}
+	}
+	if len(tokens) >= MaxFilterAlternatives {
+		return nil
+	}
+
+	options := make([][]string, 0, len(tokens)+1)
+	for _, t := range tokens {
+		options = append(options, []string{t})
+	}
+	return append(options, []string{intervalLongToken})
+}
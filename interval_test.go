@@ -0,0 +1,91 @@
+package xim
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIntervalTokens(t *testing.T) {
+	spec := TimeBucketSpec{Granularity: TimeDate}
+
+	t.Run("buckets and containing buckets", func(t *testing.T) {
+		start, end := time.Date(2020, 1, 30, 0, 0, 0, 0, time.UTC), time.Date(2020, 3, 3, 0, 0, 0, 0, time.UTC)
+		actual := IntervalTokens(start, end, spec)
+		sort.Strings(actual)
+		expected := []string{
+			"a:2020", "a:2020-01", "a:2020-01-30", "a:2020-01-31", "a:2020-02", "a:2020-03", "a:2020-03-01", "a:2020-03-02",
+			"c:2020-01-30", "c:2020-01-31", "c:2020-02", "c:2020-03-01", "c:2020-03-02",
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+	})
+
+	t.Run("long interval", func(t *testing.T) {
+		start := time.Date(2001, 2, 3, 4, 0, 0, 0, time.UTC)
+		actual := IntervalTokens(start, start.AddDate(10, 0, 0), TimeBucketSpec{Granularity: TimeHour})
+
+		covers := 0
+		for _, token := range actual {
+			if strings.HasPrefix(token, intervalCoverPrefix) {
+				covers++
+			}
+		}
+		if covers > intervalMaxBuckets {
+			t.Errorf("covering buckets expected: <= %d, but was: %d", intervalMaxBuckets, covers)
+		}
+	})
+
+	t.Run("longer than buckets by years", func(t *testing.T) {
+		start := time.Date(1500, 1, 1, 0, 0, 0, 0, time.UTC)
+		for _, end := range []time.Time{start.AddDate(600, 0, 0), time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)} {
+			actual := IntervalTokens(start, end, spec)
+			if expected := []string{intervalLongToken}; !reflect.DeepEqual(actual, expected) {
+				t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		if actual := IntervalTokens(at, at, spec); len(actual) != 0 {
+			t.Errorf("unexpected, actual: `%v`, expected: no tokens", actual)
+		}
+	})
+}
+
+func TestIntervalAtMidnightDST(t *testing.T) {
+	// DST started at midnight on 2018-11-04 in São Paulo, so the date started at 01:00
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip(err)
+	}
+	spec := TimeBucketSpec{Granularity: TimeDate, Location: saoPaulo}
+	start := time.Date(2018, 11, 3, 12, 0, 0, 0, saoPaulo)
+
+	builtIndexes := NewIndexes(nil).AddInterval("label1", start, start.Add(72*time.Hour), spec).MustBuild()
+	if !builtIndexes["label1 c:2018-11-04"] || !builtIndexes["label1 a:2018-11-03"] {
+		t.Errorf("unexpected, actual: `%v`", builtIndexes)
+	}
+
+	for _, filter := range []*Filters{
+		NewFilters(nil).AddActiveAt("label1", time.Date(2018, 11, 4, 1, 30, 0, 0, saoPaulo), spec),
+		NewFilters(nil).AddOverlap("label1",
+			time.Date(2018, 11, 4, 1, 0, 0, 0, saoPaulo), time.Date(2018, 11, 5, 0, 0, 0, 0, saoPaulo), spec),
+	} {
+		matched := false
+		for _, builtFilters := range filter.MustBuildAll() {
+			all := true
+			for builtFilter := range builtFilters {
+				all = all && builtIndexes[builtFilter]
+			}
+			matched = matched || all
+		}
+		if !matched {
+			t.Errorf("filters: %v expected: matched, but was: not matched", filter.MustBuildAll())
+		}
+	}
+}
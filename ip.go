@@ -0,0 +1,142 @@
+package xim
+
+import (
+	"net"
+	"sort"
+)
+
+// IPPrefixLengths - describes prefix lengths of IP address indexes.
+// Empty lengths mean every 8 bits for IPv4 and every 16 bits for IPv6.
+type IPPrefixLengths struct {
+	IPv4 []int // prefix lengths of IPv4 addresses in [1, 32]
+	IPv6 []int // prefix lengths of IPv6 addresses in [1, 128]
+}
+
+var defaultIPPrefixLengths = IPPrefixLengths{
+	IPv4: []int{8, 16, 24, 32},
+	IPv6: []int{16, 32, 48, 64, 80, 96, 112, 128},
+}
+
+func (p IPPrefixLengths) valid() bool {
+	for _, l := range p.IPv4 {
+		if l < 1 || l > 8*net.IPv4len {
+			return false
+		}
+	}
+	for _, l := range p.IPv6 {
+		if l < 1 || l > 8*net.IPv6len {
+			return false
+		}
+	}
+	return true
+}
+
+// lengths - returns sorted prefix lengths for addresses of the bits.
+// They include 0, which matches any address of the family.
+func (p IPPrefixLengths) lengths(bits int) []int {
+	lengths := p.IPv4
+	if bits != 8*net.IPv4len {
+		lengths = p.IPv6
+	}
+	if len(lengths) == 0 {
+		return defaultIPPrefixLengths.lengths(bits)
+	}
+
+	sorted := append([]int{0}, lengths...)
+	sort.Ints(sorted)
+	return sorted
+}
+
+// ipPrefixLengths - returns the prefix lengths of IP address indexes of the label.
+func (conf *Config) ipPrefixLengths(label string) IPPrefixLengths {
+	if p, ok := conf.IPPrefixes[label]; ok {
+		return p
+	}
+	return defaultIPPrefixLengths
+}
+
+// ipBytes - returns 4 bytes for IPv4 and 16 bytes for IPv6, or nil if ip is invalid.
+func ipBytes(ip net.IP) net.IP {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+	return ip.To16()
+}
+
+// ipPrefixToken - returns the CIDR notation of the prefix of ip, e.g. "10.2.0.0/16".
+func ipPrefixToken(ip net.IP, length int) string {
+	mask := net.CIDRMask(length, 8*len(ip))
+	return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+}
+
+// IPTokens - returns prefix tokens of ip with the prefix lengths.
+// Invalid addresses produce no tokens.
+func IPTokens(ip net.IP, prefixes IPPrefixLengths) []string {
+	ip = ipBytes(ip)
+	if ip == nil {
+		return nil
+	}
+
+	lengths := prefixes.lengths(8 * len(ip))
+	tokens := make([]string, 0, len(lengths))
+	for _, l := range lengths {
+		tokens = append(tokens, ipPrefixToken(ip, l))
+	}
+	return tokens
+}
+
+// setIPBits - returns a copy of ip whose count bits from the offset bit are replaced with v.
+func setIPBits(ip net.IP, offset, count int, v uint64) net.IP {
+	dst := append(net.IP(nil), ip...)
+	for i := 0; i < count; i++ {
+		pos := offset + i
+		bit := byte(1) << uint(7-pos%8)
+		if v>>uint(count-1-i)&1 == 1 {
+			dst[pos/8] |= bit
+		} else {
+			dst[pos/8] &^= bit
+		}
+	}
+	return dst
+}
+
+// cidrFilterOptions - returns alternative tokens for the cidr.
+// The cidr is searched as is if its length is indexed,
+// or it's split into finer indexed prefixes if they are not more than MaxFilterAlternatives,
+// or the nearest coarser indexed prefix is used. exact reports whether the tokens cover the cidr exactly.
+func cidrFilterOptions(cidr *net.IPNet, prefixes IPPrefixLengths) (options [][]string, exact bool) {
+	ones, bits := cidr.Mask.Size()
+	ip := ipBytes(cidr.IP)
+	if len(ip) == net.IPv4len && bits == 8*net.IPv6len && ones >= 8*(net.IPv6len-net.IPv4len) {
+		// IPv4-mapped IPv6 addresses are indexed as IPv4
+		ones, bits = ones-8*(net.IPv6len-net.IPv4len), 8*net.IPv4len
+	}
+	if ip == nil || bits != 8*len(ip) {
+		// non-canonical masks are never indexed
+		return [][]string{{rangeNone}}, true
+	}
+
+	lengths := prefixes.lengths(bits)
+	coarser := 0
+	for _, l := range lengths {
+		if l <= ones {
+			coarser = l
+			continue
+		}
+		if coarser == ones {
+			// the cidr itself is indexed
+			break
+		}
+		// the first finer length
+		if l-ones < 64 && uint64(1)<<uint(l-ones) <= MaxFilterAlternatives {
+			n := uint64(1) << uint(l-ones)
+			options = make([][]string, 0, n)
+			for v := uint64(0); v < n; v++ {
+				options = append(options, []string{ipPrefixToken(setIPBits(ip, ones, l-ones, v), l)})
+			}
+			return options, true
+		}
+		break
+	}
+	return [][]string{{ipPrefixToken(ip, coarser)}}, coarser == ones
+}
@@ -0,0 +1,63 @@
+package xim
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestIPTokens(t *testing.T) {
+	t.Run("IPv4", func(t *testing.T) {
+		actual := IPTokens(net.ParseIP("10.2.3.4"), IPPrefixLengths{})
+		expected := []string{"0.0.0.0/0", "10.0.0.0/8", "10.2.0.0/16", "10.2.3.0/24", "10.2.3.4/32"}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+	})
+
+	t.Run("IPv6", func(t *testing.T) {
+		actual := IPTokens(net.ParseIP("2001:db8::1"), IPPrefixLengths{IPv6: []int{64, 32}})
+		expected := []string{"::/0", "2001:db8::/32", "2001:db8::/64"}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if actual := IPTokens(nil, IPPrefixLengths{}); len(actual) != 0 {
+			t.Errorf("unexpected, actual: `%v`, expected: no tokens", actual)
+		}
+	})
+}
+
+func TestCIDRFilterOptions(t *testing.T) {
+	for _, c := range []struct {
+		cidr     string
+		expected [][]string
+		exact    bool
+	}{
+		{"10.2.0.0/16", [][]string{{"10.2.0.0/16"}}, true},
+		{"10.2.0.0/14", [][]string{{"10.0.0.0/16"}, {"10.1.0.0/16"}, {"10.2.0.0/16"}, {"10.3.0.0/16"}}, true},
+		{"10.64.0.0/10", [][]string{{"10.0.0.0/8"}}, false},
+		{"10.2.0.0/15", [][]string{{"10.2.0.0/16"}, {"10.3.0.0/16"}}, true},
+		{"10.2.3.0/30", [][]string{{"10.2.3.0/32"}, {"10.2.3.1/32"}, {"10.2.3.2/32"}, {"10.2.3.3/32"}}, true},
+		{"::ffff:10.2.0.0/112", [][]string{{"10.2.0.0/16"}}, true},
+		{"2001:db8::/40", [][]string{{"2001:db8::/32"}}, false},
+	} {
+		_, cidr, err := net.ParseCIDR(c.cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, exact := cidrFilterOptions(cidr, IPPrefixLengths{})
+		if !reflect.DeepEqual(actual, c.expected) || exact != c.exact {
+			t.Errorf("%s: unexpected, actual: `%v`, %v, expected: `%v`, %v", c.cidr, actual, exact, c.expected, c.exact)
+		}
+	}
+	t.Run("indexed length", func(t *testing.T) {
+		_, cidr, _ := net.ParseCIDR("10.2.3.0/24")
+		actual, exact := cidrFilterOptions(cidr, IPPrefixLengths{IPv4: []int{24, 25}})
+		if expected := [][]string{{"10.2.3.0/24"}}; !reflect.DeepEqual(actual, expected) || !exact {
+			t.Errorf("unexpected, actual: `%v`, %v, expected: `%v`, true", actual, exact, expected)
+		}
+	})
+}
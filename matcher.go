@@ -0,0 +1,280 @@
+package xim
+
+import (
+	"net"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Matcher - verifies values of search results with the conditions of Filters.
+// Search results can contain false positives since indexes don't record positions of tokens.
+type Matcher struct {
+	conf       *Config
+	conditions []PostFilter
+	regexps    map[string]*regexp.Regexp
+	cidrs      map[string]*net.IPNet
+}
+
+// Matcher - creates a Matcher with the conditions added to the filters so far.
+// Conditions of AddTokenized, AddStems and AddPhonetic are not verified since they have no exact forms.
+func (filters *Filters) Matcher() *Matcher {
+	m := &Matcher{
+		conf:       filters.conf,
+		conditions: append([]PostFilter(nil), filters.conditions...),
+		regexps:    make(map[string]*regexp.Regexp),
+		cidrs:      make(map[string]*net.IPNet),
+	}
+	for _, cond := range m.conditions {
+		if cond.Match == MatchRegexp {
+			// it has been compiled by the caller of Filters.AddRegexp
+			m.regexps[cond.Value] = regexp.MustCompile(cond.Value)
+		}
+		if cond.Match == MatchCIDR {
+			// it has been parsed by the caller of Filters.AddCIDR
+			if _, cidr, err := net.ParseCIDR(cond.Value); err == nil {
+				m.cidrs[cond.Value] = cidr
+			}
+		}
+	}
+	return m
+}
+
+// Match - reports whether values satisfy all the conditions.
+// values is map[label]value, whose value is the original field value indexed with the label.
+// It can be a string, a slice of strings or a Bit of InBuilder, and a slice matches if any of its elements matches.
+// Values of labels without conditions are ignored, and missing values never match.
+func (m *Matcher) Match(values map[string]interface{}) bool {
+	for _, cond := range m.conditions {
+		v, ok := values[cond.Label]
+		if !ok || !m.matchCondition(cond, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Matcher) matchCondition(cond PostFilter, v interface{}) bool {
+	switch cond.Match {
+	case MatchIn:
+		return matchIn(cond.Value, v)
+	case MatchRange:
+		return matchRange(cond.Low, cond.High, v)
+	case MatchOverlap:
+		return matchOverlap(cond.Low, cond.High, v)
+	case MatchGeoWithin, MatchGeoBox:
+		return matchGeo(cond, v)
+	case MatchCIDR:
+		return matchCIDR(m.cidrs[cond.Value], v)
+	case MatchRegexp:
+		re := m.regexps[cond.Value]
+		for _, s := range somethingTokens(v) {
+			if re.MatchString(s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	query := m.conf.normalize(cond.Value)
+	for _, s := range somethingTokens(v) {
+		if m.matchString(cond, query, m.conf.normalize(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchString - reports whether the normalized value s matches the normalized query.
+func (m *Matcher) matchString(cond PostFilter, query string, s string) bool {
+	tokenizers := m.conf.tokenizers()
+
+	switch cond.Match {
+	case MatchExact:
+		return s == query
+	case MatchPrefix, MatchSuffix:
+		for _, w := range tokenizers.words(s) {
+			if cond.Match == MatchPrefix && strings.HasPrefix(w, query) ||
+				cond.Match == MatchSuffix && strings.HasSuffix(w, query) {
+				return true
+			}
+		}
+		return false
+	case MatchPhrasePrefix:
+		return strings.HasPrefix(normalizePhrase(s), normalizePhrase(query))
+	case MatchPhraseSuffix:
+		return strings.HasSuffix(normalizePhrase(s), normalizePhrase(query))
+	case MatchPhrase:
+		words := " " + strings.Join(tokenizers.words(s), " ") + " "
+		return strings.Contains(words, " "+strings.Join(tokenizers.words(query), " ")+" ")
+	case MatchPartial:
+		return strings.Contains(strings.Join(tokenizers.words(s), " "), strings.Join(tokenizers.words(query), " "))
+	case MatchWildcard:
+		pattern := tokenizers.units(query)
+		for _, w := range tokenizers.words(s) {
+			if wildcardMatch(pattern, tokenizers.units(w)) {
+				return true
+			}
+		}
+		return false
+	case MatchFuzzy:
+		words := tokenizers.words(s)
+		for _, q := range tokenizers.words(query) {
+			qUnits := tokenizers.units(q)
+			distance := deletionDistance(len(qUnits), cond.Distance)
+			found := false
+			for _, w := range words {
+				if editDistance(qUnits, tokenizers.units(w)) <= distance {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// matchIn - reports whether v has any bit of the hexadecimal mask.
+func matchIn(mask string, v interface{}) bool {
+	maskBits, err := strconv.ParseUint(mask, 16, 64)
+	if err != nil {
+		return false
+	}
+
+	var bits []Bit
+	switch b := v.(type) {
+	case Bit:
+		bits = []Bit{b}
+	case []Bit:
+		bits = b
+	}
+	for _, b := range bits {
+		if uint64(b)&maskBits != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRange - reports whether v is a number or time.Time within [low, high), or a slice of them has any within it.
+func matchRange(low, high int64, v interface{}) bool {
+	if t, ok := v.(time.Time); ok {
+		return low <= unixNano(t) && unixNano(t) < high
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return low <= rv.Int() && rv.Int() < high
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return (low < 0 || uint64(low) <= rv.Uint()) && high > 0 && rv.Uint() < uint64(high)
+	case reflect.Float32, reflect.Float64:
+		return float64(low) <= rv.Float() && rv.Float() < float64(high)
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			if rv.Index(i).CanInterface() && matchRange(low, high, rv.Index(i).Interface()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchOverlap - reports whether v is an interval of [2]time.Time overlapping [low, high), or a slice of them has any.
+func matchOverlap(low, high int64, v interface{}) bool {
+	switch interval := v.(type) {
+	case [2]time.Time:
+		return unixNano(interval[0]) < high && low < unixNano(interval[1])
+	case [][2]time.Time:
+		for _, i := range interval {
+			if matchOverlap(low, high, i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchGeo - reports whether v is a location of [2]float64{lat, lng} within the area of cond,
+// or a slice of them has any.
+func matchGeo(cond PostFilter, v interface{}) bool {
+	switch loc := v.(type) {
+	case [2]float64:
+		a := cond.Area
+		if cond.Match == MatchGeoWithin {
+			return GeoDistance(a[0], a[1], loc[0], loc[1]) <= a[2]
+		}
+		return geoBox{minLat: a[0], minLng: a[1], maxLat: a[2], maxLng: a[3]}.contains(loc[0], loc[1])
+	case [][2]float64:
+		for _, l := range loc {
+			if matchGeo(cond, l) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchCIDR - reports whether v is an IP address of net.IP or string within the cidr, or a slice of them has any.
+func matchCIDR(cidr *net.IPNet, v interface{}) bool {
+	if cidr == nil {
+		return false
+	}
+	switch ip := v.(type) {
+	case net.IP:
+		return ip != nil && cidr.Contains(ip)
+	case []net.IP:
+		for _, i := range ip {
+			if matchCIDR(cidr, i) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, s := range somethingTokens(v) {
+		if ip := net.ParseIP(s); ip != nil && cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance - returns the optimal string alignment distance between a and b,
+// which counts insertions, deletions, substitutions and transpositions of adjacent characters.
+func editDistance(a, b []string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, minInt(d[i][j-1]+1, d[i-1][j-1]+cost))
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,236 @@
+package xim
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMatcher(t *testing.T) {
+	inBuilder := NewInBuilder()
+	status1 := inBuilder.NewBit()
+	status2 := inBuilder.NewBit()
+	status3 := inBuilder.NewBit()
+
+	conf := &Config{IgnoreCase: true}
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		filters  *Filters
+		values   map[string]interface{}
+		expected bool
+	}{
+		{
+			name:     "bigrams in order",
+			filters:  NewFilters(conf).AddBigrams("l", "abcab"),
+			values:   map[string]interface{}{"l": "xx ABCAB"},
+			expected: true,
+		},
+		{
+			name:     "bigrams out of order",
+			filters:  NewFilters(conf).AddBigrams("l", "abcab"),
+			values:   map[string]interface{}{"l": "cab ab"},
+			expected: false,
+		},
+		{
+			name:     "partial across words",
+			filters:  NewFilters(conf).AddPartial("l", "rry pot"),
+			values:   map[string]interface{}{"l": "Harry  Potter"},
+			expected: true,
+		},
+		{
+			name:     "prefix",
+			filters:  NewFilters(conf).AddPrefix("l", "pot"),
+			values:   map[string]interface{}{"l": "Harry Potter"},
+			expected: true,
+		},
+		{
+			name:     "prefix in the middle of a word",
+			filters:  NewFilters(conf).AddPrefix("l", "ott"),
+			values:   map[string]interface{}{"l": "Harry Potter"},
+			expected: false,
+		},
+		{
+			name:     "suffix",
+			filters:  NewFilters(conf).AddSuffix("l", "rry"),
+			values:   map[string]interface{}{"l": "Harry Potter"},
+			expected: true,
+		},
+		{
+			name:     "phrase prefix",
+			filters:  NewFilters(conf).AddPhrasePrefix("l", "harry p"),
+			values:   map[string]interface{}{"l": "Harry Potter"},
+			expected: true,
+		},
+		{
+			name:     "phrase suffix",
+			filters:  NewFilters(conf).AddPhraseSuffix("l", "harry"),
+			values:   map[string]interface{}{"l": "Harry Potter"},
+			expected: false,
+		},
+		{
+			name:     "phrase",
+			filters:  NewFilters(conf).AddPhrase("l", "york pizza", 2),
+			values:   map[string]interface{}{"l": "new york pizza"},
+			expected: true,
+		},
+		{
+			name:     "phrase of partial words",
+			filters:  NewFilters(conf).AddPhrase("l", "ork pizza", 2),
+			values:   map[string]interface{}{"l": "new york pizza"},
+			expected: false,
+		},
+		{
+			name:     "exact",
+			filters:  NewFilters(conf).Add("l", "5000<=p<10000"),
+			values:   map[string]interface{}{"l": "5000<=p<10000"},
+			expected: true,
+		},
+		{
+			name:     "exact in a slice",
+			filters:  NewFilters(conf).AddSomething("l", []string{"a", "b"}),
+			values:   map[string]interface{}{"l": []string{"c", "b", "a"}},
+			expected: true,
+		},
+		{
+			name:     "exact mismatch",
+			filters:  NewFilters(conf).AddSomething("l", true),
+			values:   map[string]interface{}{"l": false},
+			expected: false,
+		},
+		{
+			name:     "exact time",
+			filters:  NewFilters(conf).AddSomething("l", at),
+			values:   map[string]interface{}{"l": at},
+			expected: true,
+		},
+		{
+			name:     "IN",
+			filters:  NewFilters(conf).AddIn("l", inBuilder, status1, status3),
+			values:   map[string]interface{}{"l": status3},
+			expected: true,
+		},
+		{
+			name:     "not IN",
+			filters:  NewFilters(conf).AddIn("l", inBuilder, status1, status3),
+			values:   map[string]interface{}{"l": []Bit{status2}},
+			expected: false,
+		},
+		{
+			name:     "range",
+			filters:  NewFilters(conf).AddRange("l", 10, 20, NumberRangeSpec{Min: 0, Max: 100}),
+			values:   map[string]interface{}{"l": 19.5},
+			expected: true,
+		},
+		{
+			name:     "out of range",
+			filters:  NewFilters(conf).AddRange("l", 10, 20, NumberRangeSpec{Min: 0, Max: 100}),
+			values:   map[string]interface{}{"l": uint(20)},
+			expected: false,
+		},
+		{
+			name:     "time range",
+			filters:  NewFilters(conf).AddTimeRange("l", at, at.Add(time.Hour), TimeBucketSpec{Granularity: TimeDate}),
+			values:   map[string]interface{}{"l": at.Add(time.Minute)},
+			expected: true,
+		},
+		{
+			name:     "out of time range",
+			filters:  NewFilters(conf).AddTimeRange("l", at, at.Add(time.Hour), TimeBucketSpec{Granularity: TimeDate}),
+			values:   map[string]interface{}{"l": at.Add(-time.Minute)},
+			expected: false,
+		},
+		{
+			name:     "overlap",
+			filters:  NewFilters(conf).AddOverlap("l", at, at.Add(time.Hour), TimeBucketSpec{Granularity: TimeDate}),
+			values:   map[string]interface{}{"l": [2]time.Time{at.Add(-time.Hour), at.Add(time.Minute)}},
+			expected: true,
+		},
+		{
+			name:     "not active",
+			filters:  NewFilters(conf).AddActiveAt("l", at, TimeBucketSpec{Granularity: TimeDate}),
+			values:   map[string]interface{}{"l": [2]time.Time{at.Add(-time.Hour), at}},
+			expected: false,
+		},
+		{
+			name:     "wildcard",
+			filters:  NewFilters(conf).AddWildcard(WildcardLabels{Partial: "l"}, "ha?ry"),
+			values:   map[string]interface{}{"l": "Harry Potter"},
+			expected: true,
+		},
+		{
+			name:     "regexp",
+			filters:  NewFilters(conf).AddRegexp("l", regexp.MustCompile(`Po.+er$`)),
+			values:   map[string]interface{}{"l": "Harry Potter"},
+			expected: true,
+		},
+		{
+			name:     "regexp is case sensitive",
+			filters:  NewFilters(conf).AddRegexp("l", regexp.MustCompile(`po.+er$`)),
+			values:   map[string]interface{}{"l": "Harry Potter"},
+			expected: false,
+		},
+		{
+			name:     "fuzzy",
+			filters:  NewFilters(conf).AddFuzzy("l", "jonson", 1),
+			values:   map[string]interface{}{"l": "Jack Johnson"},
+			expected: true,
+		},
+		{
+			name:     "fuzzy farther than the distance",
+			filters:  NewFilters(conf).AddFuzzy("l", "abcd", 1),
+			values:   map[string]interface{}{"l": "bcde"},
+			expected: false,
+		},
+		{
+			name:     "stems are not verified",
+			filters:  NewFilters(conf).AddStems("l", "running"),
+			values:   map[string]interface{}{},
+			expected: true,
+		},
+		{
+			name:     "missing value",
+			filters:  NewFilters(conf).AddPrefix("l", "a"),
+			values:   map[string]interface{}{"m": "a"},
+			expected: false,
+		},
+		{
+			name: "multiple conditions",
+			filters: NewFilters(conf).
+				AddPrefix("l", "har").
+				AddIn("s", inBuilder, status2),
+			values:   map[string]interface{}{"l": "Harry Potter", "s": status1},
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if actual := c.filters.Matcher().Match(c.values); actual != c.expected {
+				t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, c.expected)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"abc", "ab", 1},
+		{"abc", "acb", 1},
+		{"kitten", "sitting", 3},
+		{"abcd", "bcde", 2},
+	}
+
+	for _, c := range cases {
+		if actual := editDistance(runeUnits(c.a), runeUnits(c.b)); actual != c.expected {
+			t.Errorf("%s, %s: unexpected, actual: `%v`, expected: `%v`", c.a, c.b, actual, c.expected)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package xim
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// Normalization - describes Unicode normalization applied to values before tokenizing.
+type Normalization int
+
+const (
+	NormalizationNone  Normalization = iota // no normalization.
+	NormalizationWidth                      // folds full-width and half-width forms only.
+	NormalizationNFKC                       // folds width and compatibility characters with NFKC.
+)
+
+func (n Normalization) valid() bool {
+	return NormalizationNone <= n && n <= NormalizationNFKC
+}
+
+func (n Normalization) apply(s string) string {
+	switch n {
+	case NormalizationWidth:
+		// compose voiced sound marks folded from half-width katakana.
+		return norm.NFC.String(width.Fold.String(s))
+	case NormalizationNFKC:
+		return norm.NFKC.String(s)
+	default:
+		return s
+	}
+}
+
+// normalize - normalizes s with the configuration.
+// It must be idempotent since it's applied to both values and their tokens.
+func (conf *Config) normalize(s string) string {
+	s = conf.Normalization.apply(s)
+	if conf.CaseFolding {
+		s = FoldCase(s, conf.CaseLocale)
+	} else if conf.IgnoreCase {
+		s = strings.ToLower(s)
+	}
+	if conf.FoldAccents {
+		s = FoldAccents(s)
+	}
+	if conf.FoldKana {
+		s = FoldKana(s)
+	}
+	return s
+}
+
+// FoldCase - folds s for case-insensitive search with Unicode full case folding.
+// Unlike strings.ToLower, "ß" is folded into "ss" and final sigma into sigma.
+// locale is a BCP 47 language tag such as "tr" or "az" to apply its own casing rules, or empty.
+func FoldCase(s, locale string) string {
+	if locale != "" {
+		// e.g. Turkish 'I' is lowered to dotless 'ı', and 'İ' to 'i'.
+		s = cases.Lower(language.Make(locale)).String(s)
+	}
+	// Caser has state, so it's created on each call to be safe for concurrent use.
+	return cases.Fold().String(s)
+}
+
+var halfwidthKatakana = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0xff61, Hi: 0xff9f, Stride: 1},
+	},
+}
+
+// widens half-width katakana only, and composes voiced sound marks.
+var widenKatakana = transform.Chain(runes.If(runes.In(halfwidthKatakana), width.Widen, nil), norm.NFC)
+
+// small kana to the large ones, in hiragana.
+var smallKana = map[rune]rune{
+	'ぁ': 'あ', 'ぃ': 'い', 'ぅ': 'う', 'ぇ': 'え', 'ぉ': 'お',
+	'っ': 'つ', 'ゃ': 'や', 'ゅ': 'ゆ', 'ょ': 'よ', 'ゎ': 'わ',
+	'ゕ': 'か', 'ゖ': 'け',
+	'ㇰ': 'く', 'ㇱ': 'し', 'ㇲ': 'す', 'ㇳ': 'と', 'ㇴ': 'ぬ', 'ㇵ': 'は', 'ㇶ': 'ひ',
+	'ㇷ': 'ふ', 'ㇸ': 'へ', 'ㇹ': 'ほ', 'ㇺ': 'む', 'ㇻ': 'ら', 'ㇼ': 'り', 'ㇽ': 'る', 'ㇾ': 'れ', 'ㇿ': 'ろ',
+}
+
+// katakana without a hiragana counterpart.
+var voicedKatakana = map[rune]string{
+	'ヷ': "わ\u3099", 'ヸ': "ゐ\u3099", 'ヹ': "ゑ\u3099", 'ヺ': "を\u3099",
+}
+
+// FoldKana - folds s for kana-insensitive search.
+// Katakana is converted to hiragana, small kana to the large ones, and prolonged sound marks are removed
+// so that "リンゴ", "りんご" and "ｻｰﾊﾞｰ", "さーば" are folded into the same forms.
+func FoldKana(s string) string {
+	s, _, _ = transform.String(widenKatakana, s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == 'ー' || r == '\uff70':
+			// prolonged sound mark
+			continue
+		case 'ァ' <= r && r <= 'ヶ', r == 'ヽ' || r == 'ヾ':
+			r -= 'ァ' - 'ぁ'
+		case voicedKatakana[r] != "":
+			b.WriteString(norm.NFC.String(voicedKatakana[r]))
+			continue
+		}
+		if large, ok := smallKana[r]; ok {
+			r = large
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// combining diacritical marks.
+// Other nonspacing marks like kana voiced sound marks and Indic vowel signs are kept.
+var diacriticalMarks = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0300, Hi: 0x036f, Stride: 1},
+		{Lo: 0x1ab0, Hi: 0x1aff, Stride: 1},
+		{Lo: 0x1dc0, Hi: 0x1dff, Stride: 1},
+		{Lo: 0x20d0, Hi: 0x20ff, Stride: 1},
+		{Lo: 0xfe20, Hi: 0xfe2f, Stride: 1},
+	},
+}
+
+// decomposes, removes diacritical marks and composes again.
+var removeDiacriticalMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(diacriticalMarks)), norm.NFC)
+
+// letters which are not decomposed into a base letter and marks.
+var specialLetters = map[rune]string{
+	'ß': "ss", 'ẞ': "SS",
+	'æ': "ae", 'Æ': "AE",
+	'œ': "oe", 'Œ': "OE",
+	'ø': "o", 'Ø': "O",
+	'đ': "d", 'Đ': "D",
+	'ð': "d", 'Ð': "D",
+	'þ': "th", 'Þ': "TH",
+	'ł': "l", 'Ł': "L",
+	'ħ': "h", 'Ħ': "H",
+	'ı': "i",
+	'ŀ': "l", 'Ŀ': "L",
+	'ŧ': "t", 'Ŧ': "T",
+}
+
+// FoldAccents - folds s for accent-insensitive search.
+// Diacritical marks are removed and special letters are spelled out,
+// so that "Café" and "Ærø" are folded into "Cafe" and "AEro".
+func FoldAccents(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if spelled, ok := specialLetters[r]; ok {
+			b.WriteString(spelled)
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	s, _, _ = transform.String(removeDiacriticalMarks, b.String())
+	return s
+}
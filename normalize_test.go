@@ -0,0 +1,67 @@
+package xim
+
+import (
+	"testing"
+)
+
+func TestFoldKana(t *testing.T) {
+	cases := map[string]string{
+		"リンゴ":     "りんご",
+		"りんご":     "りんご",
+		"ｻｰﾊﾞｰ":   "さば",
+		"サーバ":     "さば",
+		"キャッシュ":   "きやつしゆ",
+		"ヴァイオリン":  "ゔあいおりん",
+		"ヴｧｲｵﾘﾝ":  "ゔあいおりん",
+		"ヷ":       "わ゙",
+		"いすゞ":     "いすゞ",
+		"abc ABC": "abc ABC",
+	}
+
+	for s, expected := range cases {
+		if actual := FoldKana(s); actual != expected {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", s, actual, expected)
+		}
+	}
+}
+
+func TestFoldAccents(t *testing.T) {
+	cases := map[string]string{
+		"Café":         "Cafe",
+		"Ærø":          "AEro",
+		"straße":       "strasse",
+		"Crème Brûlée": "Creme Brulee",
+		"Łódź":         "Lodz",
+		"ガギグ ぱぴぷ":      "ガギグ ぱぴぷ",
+		"हिन्दी":       "हिन्दी",
+		"école":       "ecole",
+		"naïve résumé": "naive resume",
+	}
+
+	for s, expected := range cases {
+		if actual := FoldAccents(s); actual != expected {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", s, actual, expected)
+		}
+	}
+}
+
+func TestFoldCase(t *testing.T) {
+	cases := []struct {
+		s, locale, expected string
+	}{
+		{"Straße", "", "strasse"},
+		{"STRASSE", "", "strasse"},
+		{"ΟΔΥΣΣΕΥΣ", "", "οδυσσευσ"},
+		{"Οδυσσεύς", "", "οδυσσεύσ"},
+		{"DİYARBAKIR", "tr", "diyarbakır"},
+		{"diyarbakır", "tr", "diyarbakır"},
+		{"Iğdır", "az", "ığdır"},
+		{"Iğdır", "", "iğdır"},
+	}
+
+	for _, c := range cases {
+		if actual := FoldCase(c.s, c.locale); actual != c.expected {
+			t.Errorf("%s(%s): unexpected, actual: `%v`, expected: `%v`", c.s, c.locale, actual, c.expected)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package xim
+
+import (
+	"fmt"
+	"math"
+
+	"golang.org/x/xerrors"
+)
+
+// NumberRangeSpec - describes hierarchical buckets of numbers for range search.
+// Buckets of a level are Base times as large as buckets of the level below, and level 0 is each value.
+type NumberRangeSpec struct {
+	Min  int64 // minimum value to index
+	Max  int64 // maximum value to index, exclusive
+	Base int   // ratio of bucket sizes between levels, 2 for power-of-two buckets or 10 for decimal buckets. 0 means 2
+}
+
+// Validate - validates the spec.
+func (spec NumberRangeSpec) Validate() error {
+	if spec.Min >= spec.Max {
+		return xerrors.Errorf("Min of NumberRangeSpec must be less than Max: %d, %d", spec.Min, spec.Max)
+	}
+	if spec.Base < 0 || spec.Base == 1 {
+		return xerrors.Errorf("invalid Base of NumberRangeSpec: %d", spec.Base)
+	}
+	return nil
+}
+
+func (spec NumberRangeSpec) mustValidate() {
+	if err := spec.Validate(); err != nil {
+		panic(err)
+	}
+}
+
+func (spec NumberRangeSpec) base() uint64 {
+	if spec.Base == 0 {
+		return 2
+	}
+	return uint64(spec.Base)
+}
+
+// span - returns the number of values in the spec.
+func (spec NumberRangeSpec) span() uint64 {
+	return uint64(spec.Max) - uint64(spec.Min)
+}
+
+// offset - returns the offset of v from Min, clamping v into the spec.
+func (spec NumberRangeSpec) offset(v int64) uint64 {
+	switch {
+	case v < spec.Min:
+		return 0
+	case v > spec.Max:
+		return spec.span()
+	}
+	return uint64(v) - uint64(spec.Min)
+}
+
+// bucketSizes - returns the bucket size of each level.
+// The top level has a single bucket, and its size is 0 if it overflows.
+func (spec NumberRangeSpec) bucketSizes() []uint64 {
+	base := spec.base()
+	sizes := []uint64{1}
+	for size := uint64(1); size < spec.span(); {
+		if size > math.MaxUint64/base {
+			sizes = append(sizes, 0)
+			break
+		}
+		size *= base
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+func numberRangeToken(level int, id uint64) string {
+	return fmt.Sprintf("%d:%x", level, id)
+}
+
+// bucketID - returns the id of the bucket containing the offset at the level of the size.
+func bucketID(offset, size uint64) uint64 {
+	if size == 0 {
+		return 0
+	}
+	return offset / size
+}
+
+// isBucketBoundary - reports whether the offset is a boundary of buckets of the size.
+func isBucketBoundary(offset, size uint64) bool {
+	if size == 0 {
+		return offset == 0
+	}
+	return offset%size == 0
+}
+
+// NumberRangeTokens - returns bucket tokens of all the levels for value.
+// Values out of the spec produce no tokens.
+func NumberRangeTokens(value int64, spec NumberRangeSpec) []string {
+	spec.mustValidate()
+	if value < spec.Min || value >= spec.Max {
+		return nil
+	}
+
+	offset := spec.offset(value)
+	sizes := spec.bucketSizes()
+	tokens := make([]string, 0, len(sizes))
+	for level, size := range sizes {
+		tokens = append(tokens, numberRangeToken(level, bucketID(offset, size)))
+	}
+	return tokens
+}
+
+// numberRangeBuckets - returns the fewest bucket tokens covering [lo, hi) of offsets with buckets of minLevel or above.
+// The range is widened to the boundaries of minLevel buckets.
+func numberRangeBuckets(lo, hi uint64, sizes []uint64, minLevel int) []string {
+	if minLevel >= len(sizes) {
+		minLevel = len(sizes) - 1
+	}
+	s := sizes[minLevel]
+	if s == 0 || hi%s != 0 && hi/s+1 > math.MaxUint64/s {
+		// the widened range overflows
+		return []string{numberRangeToken(len(sizes)-1, 0)}
+	}
+	lo = lo / s * s
+	if hi%s != 0 {
+		hi = (hi/s + 1) * s
+	}
+
+	tokens := make([]string, 0, 32)
+	for level := minLevel; lo < hi; level++ {
+		size := sizes[level]
+		if level == len(sizes)-1 {
+			// the top level has a single bucket
+			tokens = append(tokens, numberRangeToken(level, 0))
+			break
+		}
+
+		next := sizes[level+1]
+		for lo < hi && !isBucketBoundary(lo, next) {
+			tokens = append(tokens, numberRangeToken(level, lo/size))
+			lo += size
+		}
+		for lo < hi && !isBucketBoundary(hi, next) {
+			hi -= size
+			tokens = append(tokens, numberRangeToken(level, hi/size))
+		}
+	}
+	return tokens
+}
+
+// rangeNone - token never indexed, which is used for empty ranges.
+const rangeNone = "-"
+
+// numberRangeFilterOptions - returns alternative tokens for [lo, hi).
+// Buckets are coarsened if there are too many, and exact reports whether they cover the range exactly.
+func numberRangeFilterOptions(lo, hi int64, spec NumberRangeSpec) (options [][]string, exact bool) {
+	spec.mustValidate()
+
+	loOffset, hiOffset := spec.offset(lo), spec.offset(hi)
+	if lo >= hi || loOffset >= hiOffset {
+		return [][]string{{rangeNone}}, true
+	}
+
+	sizes := spec.bucketSizes()
+	for minLevel := 0; minLevel < len(sizes); minLevel++ {
+		tokens := numberRangeBuckets(loOffset, hiOffset, sizes, minLevel)
+		if len(tokens) > MaxFilterAlternatives {
+			continue
+		}
+
+		options = make([][]string, 0, len(tokens))
+		for _, t := range tokens {
+			options = append(options, []string{t})
+		}
+		// ranges out of the spec are clamped
+		exact = minLevel == 0 && lo >= spec.Min && hi <= spec.Max
+		return options, exact
+	}
+	// unreachable since the top level has a single bucket
+	return [][]string{{numberRangeToken(len(sizes)-1, 0)}}, false
+}
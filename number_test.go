@@ -0,0 +1,111 @@
+package xim
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNumberRangeTokens(t *testing.T) {
+	spec := NumberRangeSpec{Min: 0, Max: 16}
+
+	actual := NumberRangeTokens(5, spec)
+	expected := []string{"0:5", "1:2", "2:1", "3:0", "4:0"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+	}
+
+	for _, v := range []int64{-1, 16} {
+		if tokens := NumberRangeTokens(v, spec); len(tokens) != 0 {
+			t.Errorf("%d: unexpected, actual: `%v`, expected: no tokens", v, tokens)
+		}
+	}
+
+	actual = NumberRangeTokens(-95, NumberRangeSpec{Min: -100, Max: 100, Base: 10})
+	expected = []string{"0:5", "1:0", "2:0", "3:0"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+	}
+}
+
+func TestNumberRangeFilterOptions(t *testing.T) {
+	tokensOf := func(options [][]string) []string {
+		tokens := make([]string, 0, len(options))
+		for _, o := range options {
+			tokens = append(tokens, o...)
+		}
+		sort.Strings(tokens)
+		return tokens
+	}
+
+	t.Run("power-of-two", func(t *testing.T) {
+		options, exact := numberRangeFilterOptions(3, 13, NumberRangeSpec{Min: 0, Max: 16})
+		expected := []string{"0:3", "0:c", "2:1", "2:2"}
+		if actual := tokensOf(options); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+		if !exact {
+			t.Errorf("expected: exact, but was: not exact")
+		}
+	})
+
+	t.Run("decimal", func(t *testing.T) {
+		options, exact := numberRangeFilterOptions(120, 350, NumberRangeSpec{Min: 0, Max: 1000, Base: 10})
+		// 120-199, 200-299 and 300-349
+		if len(options) != 14 {
+			t.Errorf("len(options) expected: %d, but was: %d", 14, len(options))
+		}
+		if !exact {
+			t.Errorf("expected: exact, but was: not exact")
+		}
+	})
+
+	t.Run("whole range", func(t *testing.T) {
+		options, exact := numberRangeFilterOptions(0, 16, NumberRangeSpec{Min: 0, Max: 16})
+		if !reflect.DeepEqual(options, [][]string{{"4:0"}}) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", options, [][]string{{"4:0"}})
+		}
+		if !exact {
+			t.Errorf("expected: exact, but was: not exact")
+		}
+	})
+
+	t.Run("coarsened", func(t *testing.T) {
+		options, exact := numberRangeFilterOptions(1, 1<<32-1, NumberRangeSpec{Min: 0, Max: 1 << 32})
+		if len(options) > MaxFilterAlternatives {
+			t.Errorf("len(options) expected: <= %d, but was: %d", MaxFilterAlternatives, len(options))
+		}
+		if exact {
+			t.Errorf("expected: not exact, but was: exact")
+		}
+	})
+
+	t.Run("clamped", func(t *testing.T) {
+		options, exact := numberRangeFilterOptions(-10, 4, NumberRangeSpec{Min: 0, Max: 16})
+		if !reflect.DeepEqual(options, [][]string{{"2:0"}}) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", options, [][]string{{"2:0"}})
+		}
+		if exact {
+			t.Errorf("expected: not exact, but was: exact")
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		options, _ := numberRangeFilterOptions(4, 4, NumberRangeSpec{Min: 0, Max: 16})
+		if !reflect.DeepEqual(options, [][]string{{rangeNone}}) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", options, [][]string{{rangeNone}})
+		}
+	})
+}
+
+func TestNumberRangeSpecValidate(t *testing.T) {
+	for _, spec := range []NumberRangeSpec{
+		{Min: 0, Max: 0},
+		{Min: 0, Max: 10, Base: 1},
+		{Min: 0, Max: 10, Base: -2},
+	} {
+		if err := spec.Validate(); err == nil {
+			t.Errorf("%+v: expected: error, but was: nil", spec)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package xim
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Phonetic - describes a phonetic algorithm to encode names.
+type Phonetic int
+
+const (
+	PhoneticSoundex         Phonetic = iota + 1 // American Soundex. e.g. "Robert" and "Rupert" are "R163".
+	PhoneticMetaphone                           // Metaphone. e.g. "Katherine" and "Catherine" are "K0RN".
+	PhoneticDoubleMetaphone                     // Double Metaphone. e.g. "Smith" and "Schmidt" share "XMT".
+)
+
+// Encode - returns the phonetic code of word, or empty if word has no latin letters.
+// It's the primary code for PhoneticDoubleMetaphone.
+func (p Phonetic) Encode(word string) string {
+	switch p {
+	case PhoneticSoundex:
+		return Soundex(word)
+	case PhoneticMetaphone:
+		return Metaphone(word)
+	case PhoneticDoubleMetaphone:
+		primary, _ := DoubleMetaphone(word)
+		return primary
+	}
+	return ""
+}
+
+// codes - returns the phonetic codes of word, which are the primary and alternate codes for PhoneticDoubleMetaphone.
+func (p Phonetic) codes(word string) []string {
+	if p != PhoneticDoubleMetaphone {
+		if code := p.Encode(word); code != "" {
+			return []string{code}
+		}
+		return nil
+	}
+
+	primary, alternate := DoubleMetaphone(word)
+	codes := make([]string, 0, 2)
+	if primary != "" {
+		codes = append(codes, primary)
+	}
+	if alternate != "" && alternate != primary {
+		codes = append(codes, alternate)
+	}
+	return codes
+}
+
+// PhoneticCodes - returns phonetic code tokens of words in s.
+// Both primary and alternate codes are returned for PhoneticDoubleMetaphone.
+func PhoneticCodes(s string, p Phonetic) []string {
+	return phoneticCodes(s, p, isDefaultSeparator)
+}
+
+func phoneticCodes(s string, p Phonetic, isSeparator func(rune) bool) []string {
+	tokenMap := make(map[string]struct{})
+	for _, w := range strings.FieldsFunc(s, isSeparator) {
+		for _, code := range p.codes(w) {
+			tokenMap[code] = struct{}{}
+		}
+	}
+	return tokenSlice(tokenMap)
+}
+
+// phoneticPrimaryCodes - returns the primary phonetic code tokens of words in s.
+func phoneticPrimaryCodes(s string, p Phonetic, isSeparator func(rune) bool) []string {
+	tokenMap := make(map[string]struct{})
+	for _, w := range strings.FieldsFunc(s, isSeparator) {
+		if code := p.Encode(w); code != "" {
+			tokenMap[code] = struct{}{}
+		}
+	}
+	return tokenSlice(tokenMap)
+}
+
+// phoneticLetters - returns upper case latin letters of word, removing accents and other characters.
+func phoneticLetters(word string) []byte {
+	letters := make([]byte, 0, len(word))
+	for _, r := range strings.ToUpper(FoldAccents(word)) {
+		if 'A' <= r && r <= 'Z' {
+			letters = append(letters, byte(r))
+		} else if unicode.IsLetter(r) {
+			// non-latin words can't be encoded
+			return nil
+		}
+	}
+	return letters
+}
+
+var soundexDigits = [26]byte{
+	// A    B    C    D    E    F    G    H    I    J    K    L    M
+	'0', '1', '2', '3', '0', '1', '2', 'h', '0', '2', '2', '4', '5',
+	// N    O    P    Q    R    S    T    U    V    W    X    Y    Z
+	'5', '0', '1', '2', '6', '2', '3', '0', '1', 'h', '2', '0', '2',
+}
+
+// Soundex - returns the American Soundex code of word.
+// e.g. "Smith" and "Smyth" are "S530".
+func Soundex(word string) string {
+	letters := phoneticLetters(word)
+	if len(letters) == 0 {
+		return ""
+	}
+
+	code := []byte{letters[0]}
+	last := soundexDigits[letters[0]-'A']
+	for _, c := range letters[1:] {
+		digit := soundexDigits[c-'A']
+		switch digit {
+		case 'h':
+			// H and W don't separate the same digits
+			continue
+		case '0':
+			// vowels separate the same digits
+			last = digit
+			continue
+		}
+		if digit != last {
+			code = append(code, digit)
+			if len(code) == 4 {
+				break
+			}
+		}
+		last = digit
+	}
+
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+func isMetaphoneVowel(c byte) bool {
+	switch c {
+	case 'A', 'E', 'I', 'O', 'U':
+		return true
+	}
+	return false
+}
+
+// Metaphone - returns the Metaphone code of word, which is based on English pronunciation.
+// '0' in the code stands for "th".
+// e.g. "Katherine" and "Catherine" are "K0RN", "Smith" and "Smyth" are "SM0".
+func Metaphone(word string) string {
+	w := phoneticLetters(word)
+	if len(w) == 0 {
+		return ""
+	}
+
+	// initial exceptions
+	switch {
+	case len(w) > 1 && string(w[:2]) == "AE",
+		len(w) > 1 && (string(w[:2]) == "GN" || string(w[:2]) == "KN" || string(w[:2]) == "PN" || string(w[:2]) == "WR"):
+		w = w[1:]
+	case w[0] == 'X':
+		w[0] = 'S'
+	case len(w) > 1 && string(w[:2]) == "WH":
+		w = append([]byte{'W'}, w[2:]...)
+	}
+
+	at := func(i int) byte {
+		if i < 0 || i >= len(w) {
+			return 0
+		}
+		return w[i]
+	}
+	next := func(i int, s string) bool {
+		return strings.HasPrefix(string(w[i+1:]), s)
+	}
+
+	code := make([]byte, 0, len(w))
+	for i, c := range w {
+		// skip duplicate letters except C
+		if c != 'C' && at(i-1) == c {
+			continue
+		}
+
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U':
+			if i == 0 {
+				code = append(code, c)
+			}
+		case 'B':
+			// silent at the end after M. e.g. "dumb"
+			if !(i == len(w)-1 && at(i-1) == 'M') {
+				code = append(code, 'B')
+			}
+		case 'C':
+			switch {
+			case next(i, "IA") || next(i, "H"):
+				if at(i-1) == 'S' {
+					code = append(code, 'K') // "SCH"
+				} else {
+					code = append(code, 'X')
+				}
+			case next(i, "I") || next(i, "E") || next(i, "Y"):
+				if at(i-1) != 'S' {
+					code = append(code, 'S')
+				}
+			default:
+				code = append(code, 'K')
+			}
+		case 'D':
+			if next(i, "GE") || next(i, "GY") || next(i, "GI") {
+				code = append(code, 'J')
+			} else {
+				code = append(code, 'T')
+			}
+		case 'G':
+			switch {
+			case next(i, "H") && !(i+2 >= len(w) || isMetaphoneVowel(at(i+2))):
+				// silent in "GH" not at the end nor before a vowel. e.g. "night"
+			case next(i, "N") && (i+2 == len(w) || string(w[i+1:]) == "NED"):
+				// silent in "GN" or "GNED" at the end. e.g. "sign", "signed"
+			case (next(i, "I") || next(i, "E") || next(i, "Y")) && at(i-1) != 'G':
+				code = append(code, 'J')
+			default:
+				code = append(code, 'K')
+			}
+		case 'H':
+			switch at(i - 1) {
+			case 'C', 'S', 'P', 'T', 'G':
+				// a part of the previous letter
+			default:
+				if !(isMetaphoneVowel(at(i-1)) && !isMetaphoneVowel(at(i+1))) {
+					code = append(code, 'H')
+				}
+			}
+		case 'K':
+			if at(i-1) != 'C' {
+				code = append(code, 'K')
+			}
+		case 'P':
+			if next(i, "H") {
+				code = append(code, 'F')
+			} else {
+				code = append(code, 'P')
+			}
+		case 'Q':
+			code = append(code, 'K')
+		case 'S':
+			if next(i, "H") || next(i, "IO") || next(i, "IA") {
+				code = append(code, 'X')
+			} else {
+				code = append(code, 'S')
+			}
+		case 'T':
+			switch {
+			case next(i, "IA") || next(i, "IO"):
+				code = append(code, 'X')
+			case next(i, "H"):
+				code = append(code, '0')
+			case next(i, "CH"):
+				// silent in "TCH"
+			default:
+				code = append(code, 'T')
+			}
+		case 'V':
+			code = append(code, 'F')
+		case 'W', 'Y':
+			if isMetaphoneVowel(at(i + 1)) {
+				code = append(code, c)
+			}
+		case 'X':
+			code = append(code, 'K', 'S')
+		case 'Z':
+			code = append(code, 'S')
+		default: // F, J, L, M, N, R
+			code = append(code, c)
+		}
+	}
+
+	return string(code)
+}
@@ -0,0 +1,85 @@
+package xim
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSoundex(t *testing.T) {
+	cases := map[string]string{
+		"Robert":   "R163",
+		"Rupert":   "R163",
+		"Rubin":    "R150",
+		"Ashcraft": "A261",
+		"Ashcroft": "A261",
+		"Tymczak":  "T522",
+		"Pfister":  "P236",
+		"Smith":    "S530",
+		"smyth":    "S530",
+		"Lee":      "L000",
+		"Müller":   "M460",
+		"":         "",
+		"123":      "",
+		"東京":       "",
+	}
+
+	for word, expected := range cases {
+		if actual := Soundex(word); actual != expected {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", word, actual, expected)
+		}
+	}
+}
+
+func TestMetaphone(t *testing.T) {
+	cases := map[string]string{
+		"Katherine": "K0RN",
+		"Catherine": "K0RN",
+		"Smith":     "SM0",
+		"Smyth":     "SM0",
+		"Knight":    "NT",
+		"Wright":    "RT",
+		"Philip":    "FLP",
+		"Xavier":    "SFR",
+		"dumb":      "TM",
+		"Müller":    "MLR",
+		"":          "",
+		"東京":        "",
+	}
+
+	for word, expected := range cases {
+		if actual := Metaphone(word); actual != expected {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", word, actual, expected)
+		}
+	}
+}
+
+func TestPhoneticCodes(t *testing.T) {
+	actual := PhoneticCodes("Jon Smith Smyth 42", PhoneticSoundex)
+	sort.Strings(actual)
+	expected := []string{"J500", "S530"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+	}
+}
+
+func TestDoubleMetaphone(t *testing.T) {
+	for word, expected := range map[string][2]string{
+		"Smith":     {"SM0", "XMT"},
+		"Schmidt":   {"XMT", "SMT"},
+		"Katherine": {"K0RN", "KTRN"},
+		"Catherine": {"K0RN", "KTRN"},
+		"Michael":   {"MKL", "MXL"},
+		"Xavier":    {"SF", "SFR"},
+		"Jose":      {"HS", "HS"},
+		"Wasserman": {"ASRM", "FSRM"},
+		"Knight":    {"NT", "NT"},
+		"Müller":    {"MLR", "MLR"},
+		"山田":        {"", ""},
+	} {
+		primary, alternate := DoubleMetaphone(word)
+		if [2]string{primary, alternate} != expected {
+			t.Errorf("%s: unexpected, actual: `%s`, `%s`, expected: `%v`", word, primary, alternate, expected)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package xim
+
+// MatchKind - describes how a PostFilter matches values.
+type MatchKind int
+
+const (
+	MatchPrefix       MatchKind = iota + 1 // any word of the value starts with PostFilter.Value
+	MatchSuffix                            // any word of the value ends with PostFilter.Value
+	MatchPhrasePrefix                      // the whole value starts with PostFilter.Value
+	MatchPhraseSuffix                      // the whole value ends with PostFilter.Value
+	MatchPhrase                            // the value contains words of PostFilter.Value in order
+	MatchFuzzy                             // the value has words within PostFilter.Distance edits of PostFilter.Value
+	MatchWildcard                          // any word of the value matches the pattern PostFilter.Value. See WildcardMatch
+	MatchRegexp                            // the value matches the regular expression PostFilter.Value
+	MatchPartial                           // the value contains PostFilter.Value
+	MatchExact                             // the value is PostFilter.Value, or a slice value contains it
+	MatchIn                                // the value is a Bit included in the hexadecimal mask PostFilter.Value
+	MatchRange                             // the value or its UnixNano is within [PostFilter.Low, PostFilter.High)
+	MatchOverlap                           // the [2]time.Time value overlaps [PostFilter.Low, PostFilter.High) in UnixNano
+	MatchGeoWithin                         // the value is a location of [2]float64{lat, lng} within PostFilter.Area
+	MatchGeoBox                            // the value is a location of [2]float64{lat, lng} in the box PostFilter.Area
+	MatchCIDR                              // the value is an IP address within the CIDR PostFilter.Value
+)
+
+// PostFilter - describes a condition which Filters can't express exactly.
+// Search results can contain false positives, so they should be verified with the condition after the search.
+type PostFilter struct {
+	Label    string     // label of the filters
+	Match    MatchKind  // how to match values
+	Value    string     // the query as it was added
+	Distance int        // maximum edit distance for MatchFuzzy
+	Low      int64      // lower bound for MatchRange and MatchOverlap, inclusive
+	High     int64      // upper bound for MatchRange and MatchOverlap, exclusive
+	Area     [4]float64 // {lat, lng, radius, 0} for MatchGeoWithin, {min lat, min lng, max lat, max lng} for MatchGeoBox
+}
@@ -0,0 +1,263 @@
+package xim
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"unicode"
+)
+
+const (
+	regexpMaxExact = 16 // maximum number of exact strings tracked for a sub-expression
+	regexpMaxClass = 4  // maximum number of characters of a class expanded into exact strings
+)
+
+// regexpPlan - literals which matches of a regexp contain.
+// It's OR of AND of literals, and a branch without literals matches anything.
+type regexpPlan [][]string
+
+var regexpAny = regexpPlan{{}}
+
+// and - returns a plan which requires both p and q.
+// It's weakened to the smaller one if there are too many branches.
+func (p regexpPlan) and(q regexpPlan) regexpPlan {
+	if len(p)*len(q) > MaxFilterAlternatives {
+		if len(p) <= len(q) {
+			return p
+		}
+		return q
+	}
+
+	r := make(regexpPlan, 0, len(p)*len(q))
+	for _, a := range p {
+		for _, b := range q {
+			branch := make([]string, 0, len(a)+len(b))
+			r = append(r, append(append(branch, a...), b...))
+		}
+	}
+	return r
+}
+
+// or - returns a plan which requires either p or q.
+// It matches anything if there are too many branches.
+func (p regexpPlan) or(q regexpPlan) regexpPlan {
+	r := append(append(make(regexpPlan, 0, len(p)+len(q)), p...), q...)
+	if len(r) > MaxFilterAlternatives {
+		return regexpAny
+	}
+	for _, branch := range r {
+		if len(branch) == 0 {
+			return regexpAny
+		}
+	}
+	return r
+}
+
+// regexpInfo - analysis of a sub-expression.
+type regexpInfo struct {
+	exact []string   // all the strings the sub-expression matches, or nil if they're unknown
+	plan  regexpPlan // literals which matches contain, used if exact is nil
+}
+
+var regexpAnyInfo = regexpInfo{plan: regexpAny}
+
+func regexpExact(exact ...string) regexpInfo {
+	return regexpInfo{exact: exact}
+}
+
+func (info regexpInfo) toPlan() regexpPlan {
+	if info.exact == nil {
+		return info.plan
+	}
+	p := make(regexpPlan, 0, len(info.exact))
+	for _, s := range info.exact {
+		if s == "" {
+			return regexpAny
+		}
+		p = append(p, []string{s})
+	}
+	return p
+}
+
+// concatRegexpInfo - returns the analysis of a followed by b.
+func concatRegexpInfo(a, b regexpInfo) regexpInfo {
+	if a.exact != nil && b.exact != nil && len(a.exact)*len(b.exact) <= regexpMaxExact {
+		exactSet := make(map[string]struct{}, len(a.exact)*len(b.exact))
+		exact := make([]string, 0, len(a.exact)*len(b.exact))
+		for _, x := range a.exact {
+			for _, y := range b.exact {
+				if _, ok := exactSet[x+y]; !ok {
+					exactSet[x+y] = struct{}{}
+					exact = append(exact, x+y)
+				}
+			}
+		}
+		return regexpExact(exact...)
+	}
+	return regexpInfo{plan: a.toPlan().and(b.toPlan())}
+}
+
+// alternateRegexpInfo - returns the analysis of a or b.
+func alternateRegexpInfo(a, b regexpInfo) regexpInfo {
+	if a.exact != nil && b.exact != nil && len(a.exact)+len(b.exact) <= regexpMaxExact {
+		exactSet := make(map[string]struct{}, len(a.exact)+len(b.exact))
+		exact := make([]string, 0, len(a.exact)+len(b.exact))
+		for _, s := range append(append([]string{}, a.exact...), b.exact...) {
+			if _, ok := exactSet[s]; !ok {
+				exactSet[s] = struct{}{}
+				exact = append(exact, s)
+			}
+		}
+		return regexpExact(exact...)
+	}
+	return regexpInfo{plan: a.toPlan().or(b.toPlan())}
+}
+
+// runeFolds - returns r and the other cases of r.
+func runeFolds(r rune) []string {
+	folds := []string{string(r)}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		folds = append(folds, string(f))
+	}
+	return folds
+}
+
+// analyzeRegexp - analyzes re.
+// Case-insensitive literals are analyzed as they are if ignoreCase is true, since tokens are normalized.
+func analyzeRegexp(re *syntax.Regexp, ignoreCase bool) regexpInfo {
+	switch re.Op {
+	case syntax.OpEmptyMatch,
+		syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return regexpExact("")
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase == 0 || ignoreCase {
+			return regexpExact(string(re.Rune))
+		}
+		info := regexpExact("")
+		for _, r := range re.Rune {
+			info = concatRegexpInfo(info, regexpExact(runeFolds(r)...))
+		}
+		return info
+	case syntax.OpCharClass:
+		// re.Rune is pairs of ranges
+		exact := make([]string, 0, regexpMaxClass)
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				if len(exact) == regexpMaxClass {
+					return regexpAnyInfo
+				}
+				exact = append(exact, string(r))
+			}
+		}
+		if len(exact) == 0 {
+			return regexpAnyInfo
+		}
+		return regexpExact(exact...)
+	case syntax.OpCapture:
+		return analyzeRegexp(re.Sub[0], ignoreCase)
+	case syntax.OpQuest:
+		sub := analyzeRegexp(re.Sub[0], ignoreCase)
+		if sub.exact != nil {
+			return alternateRegexpInfo(sub, regexpExact(""))
+		}
+		return regexpAnyInfo
+	case syntax.OpPlus:
+		return regexpInfo{plan: analyzeRegexp(re.Sub[0], ignoreCase).toPlan()}
+	case syntax.OpRepeat:
+		if re.Min == 0 {
+			return regexpAnyInfo
+		}
+		return regexpInfo{plan: analyzeRegexp(re.Sub[0], ignoreCase).toPlan()}
+	case syntax.OpConcat:
+		info := regexpExact("")
+		for _, sub := range re.Sub {
+			info = concatRegexpInfo(info, analyzeRegexp(sub, ignoreCase))
+		}
+		return info
+	case syntax.OpAlternate:
+		info := analyzeRegexp(re.Sub[0], ignoreCase)
+		for _, sub := range re.Sub[1:] {
+			info = alternateRegexpInfo(info, analyzeRegexp(sub, ignoreCase))
+		}
+		return info
+	}
+	// OpNoMatch, OpAnyChar, OpAnyCharNotNL and OpStar
+	return regexpAnyInfo
+}
+
+// RegexpBigrams - returns bigram tokens which values matching re contain.
+// The result is OR of AND of tokens, i.e. values contain all the tokens of at least one of the slices.
+// It returns nil if no tokens are required.
+func RegexpBigrams(re *regexp.Regexp) [][]string {
+	return DefaultConfig.regexpBigrams(re)
+}
+
+// regexpBigrams - returns bigram tokens for re normalized with conf.
+func (conf *Config) regexpBigrams(re *regexp.Regexp) [][]string {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		// unreachable since re has been compiled
+		return nil
+	}
+
+	tokenizers := conf.tokenizers()
+	plan := analyzeRegexp(parsed.Simplify(), conf.IgnoreCase || conf.CaseFolding).toPlan()
+
+	branches := make([][]string, 0, len(plan))
+	for _, literals := range plan {
+		tokenMap := make(map[string]struct{})
+		for _, l := range literals {
+			l = conf.normalize(l)
+			// single characters are not indexed as bigrams
+			if tokenizers.length(l) < 2 {
+				continue
+			}
+			for _, t := range tokenizers.bigrams.FilterTokens(l) {
+				tokenMap[t] = struct{}{}
+			}
+		}
+		if len(tokenMap) == 0 {
+			// the branch matches anything
+			return nil
+		}
+
+		tokens := tokenSlice(tokenMap)
+		sort.Strings(tokens)
+		branches = append(branches, tokens)
+	}
+
+	// a branch containing all the tokens of another branch is redundant
+	sort.SliceStable(branches, func(i, j int) bool {
+		return len(branches[i]) < len(branches[j])
+	})
+	reduced := make([][]string, 0, len(branches))
+	for _, b := range branches {
+		redundant := false
+		for _, r := range reduced {
+			if containsAllTokens(b, r) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			reduced = append(reduced, b)
+		}
+	}
+	return reduced
+}
+
+// containsAllTokens - reports whether tokens contains all the sub tokens.
+func containsAllTokens(tokens, sub []string) bool {
+	tokenSet := make(map[string]struct{}, len(tokens))
+	for _, t := range tokens {
+		tokenSet[t] = struct{}{}
+	}
+	for _, t := range sub {
+		if _, ok := tokenSet[t]; !ok {
+			return false
+		}
+	}
+	return true
+}
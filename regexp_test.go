@@ -0,0 +1,34 @@
+package xim
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRegexpBigrams(t *testing.T) {
+	cases := map[string][][]string{
+		"hello":        {{"el", "he", "ll", "lo"}},
+		"^hel+o$":      {{"he"}},
+		"(foo|bar)baz": {{"ar", "az", "ba", "rb"}, {"az", "ba", "fo", "ob", "oo"}},
+		"err(or)?":     {{"er", "rr"}},
+		"[ab]c":        {{"ac"}, {"bc"}},
+		"(?i)ab":       {{"AB"}, {"Ab"}, {"aB"}, {"ab"}},
+		"foo.*bar":     {{"ar", "ba", "fo", "oo"}},
+		"(foo){2,}":    {{"fo", "oo"}},
+		"(foo)*bar":    {{"ar", "ba"}},
+		"foo|.*":       nil,
+		"a.c":          nil,
+		"[a-z]+":       nil,
+	}
+
+	for expr, expected := range cases {
+		actual := RegexpBigrams(regexp.MustCompile(expr))
+		if len(actual) == 0 && len(expected) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", expr, actual, expected)
+		}
+	}
+}
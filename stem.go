@@ -0,0 +1,308 @@
+package xim
+
+import (
+	"strings"
+	"unicode"
+)
+
+// englishStopwords - words which are too common to be indexed by Stems.
+var englishStopwords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "but": {}, "by": {},
+	"for": {}, "if": {}, "in": {}, "into": {}, "is": {}, "it": {}, "no": {}, "not": {}, "of": {},
+	"on": {}, "or": {}, "such": {}, "that": {}, "the": {}, "their": {}, "then": {}, "there": {},
+	"these": {}, "they": {}, "this": {}, "to": {}, "was": {}, "will": {}, "with": {},
+}
+
+// IsStopword - reports whether word is an English stopword, which is ignored by Stems.
+func IsStopword(word string) bool {
+	_, ok := englishStopwords[strings.ToLower(word)]
+	return ok
+}
+
+// Stem - returns the lowercase stem of an English word with the Porter stemming algorithm.
+// e.g. "Running" and "runs" are stemmed into "run".
+// Words which contain letters other than ASCII ones are returned as is.
+func Stem(word string) string {
+	lower := strings.ToLower(word)
+	for i := 0; i < len(lower); i++ {
+		if lower[i] < 'a' || 'z' < lower[i] {
+			return word
+		}
+	}
+	return porterStem([]byte(lower))
+}
+
+// stemWords - splits s into words for stemming.
+// Characters other than letters and digits split words too, and possessive "'s" is removed.
+func stemWords(s string, isSeparator func(rune) bool) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return isSeparator(r) || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’')
+	})
+
+	words := make([]string, 0, len(fields))
+	for _, w := range fields {
+		w = strings.TrimSuffix(strings.TrimSuffix(w, "'s"), "’s")
+		w = strings.NewReplacer("'", "", "’", "").Replace(w)
+		if w != "" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// porterStemmer - state of the Porter stemming algorithm.
+// b[:k+1] is the current word, and j is the end of the stem for the current suffix.
+// See https://tartarus.org/martin/PorterStemmer/
+type porterStemmer struct {
+	b    []byte
+	k, j int
+}
+
+func porterStem(b []byte) string {
+	if len(b) <= 2 {
+		return string(b)
+	}
+
+	z := &porterStemmer{b: b, k: len(b) - 1}
+	z.step1ab()
+	if z.k > 0 {
+		z.step1c()
+		z.step2()
+		z.step3()
+		z.step4()
+		z.step5()
+	}
+	return string(z.b[:z.k+1])
+}
+
+// cons - reports whether b[i] is a consonant.
+func (z *porterStemmer) cons(i int) bool {
+	switch z.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		if i == 0 {
+			return true
+		}
+		return !z.cons(i - 1)
+	}
+	return true
+}
+
+// m - measures the number of consonant sequences between 0 and j.
+// <c><v> gives 0, <c>vc<v> gives 1, <c>vcvc<v> gives 2 and so on.
+func (z *porterStemmer) m() int {
+	n := 0
+	i := 0
+	for {
+		if i > z.j {
+			return n
+		}
+		if !z.cons(i) {
+			break
+		}
+		i++
+	}
+	i++
+	for {
+		for {
+			if i > z.j {
+				return n
+			}
+			if z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+		n++
+		for {
+			if i > z.j {
+				return n
+			}
+			if !z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+	}
+}
+
+// vowelInStem - reports whether b[:j+1] contains a vowel.
+func (z *porterStemmer) vowelInStem() bool {
+	for i := 0; i <= z.j; i++ {
+		if !z.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doublec - reports whether b[j-1:j+1] is a double consonant.
+func (z *porterStemmer) doublec(j int) bool {
+	if j < 1 || z.b[j] != z.b[j-1] {
+		return false
+	}
+	return z.cons(j)
+}
+
+// cvc - reports whether b[i-2:i+1] is consonant-vowel-consonant and the last one is not w, x or y.
+// It's used to restore an 'e' at the end of a short word. e.g. cav(e), lov(e), hop(e), crim(e).
+func (z *porterStemmer) cvc(i int) bool {
+	if i < 2 || !z.cons(i) || z.cons(i-1) || !z.cons(i-2) {
+		return false
+	}
+	switch z.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends - reports whether b[:k+1] ends with s, and sets j to the end of the stem.
+func (z *porterStemmer) ends(s string) bool {
+	l := len(s)
+	if l > z.k+1 || string(z.b[z.k+1-l:z.k+1]) != s {
+		return false
+	}
+	z.j = z.k - l
+	return true
+}
+
+// setto - replaces b[j+1:k+1] with s.
+func (z *porterStemmer) setto(s string) {
+	z.b = append(z.b[:z.j+1], s...)
+	z.k = z.j + len(s)
+}
+
+// r - replaces the suffix with s if m() > 0.
+func (z *porterStemmer) r(s string) {
+	if z.m() > 0 {
+		z.setto(s)
+	}
+}
+
+// step1ab - gets rid of plurals and -ed or -ing.
+func (z *porterStemmer) step1ab() {
+	if z.b[z.k] == 's' {
+		switch {
+		case z.ends("sses"):
+			z.k -= 2
+		case z.ends("ies"):
+			z.setto("i")
+		case z.k > 0 && z.b[z.k-1] != 's':
+			z.k--
+		}
+	}
+
+	if z.ends("eed") {
+		if z.m() > 0 {
+			z.k--
+		}
+		return
+	}
+
+	if (z.ends("ed") || z.ends("ing")) && z.vowelInStem() {
+		z.k = z.j
+		switch {
+		case z.ends("at"):
+			z.setto("ate")
+		case z.ends("bl"):
+			z.setto("ble")
+		case z.ends("iz"):
+			z.setto("ize")
+		case z.doublec(z.k):
+			z.k--
+			switch z.b[z.k] {
+			case 'l', 's', 'z':
+				z.k++
+			}
+		default:
+			z.j = z.k
+			if z.m() == 1 && z.cvc(z.k) {
+				z.setto("e")
+			}
+		}
+	}
+}
+
+// step1c - turns terminal y to i when there is another vowel in the stem.
+func (z *porterStemmer) step1c() {
+	if z.ends("y") && z.vowelInStem() {
+		z.b[z.k] = 'i'
+	}
+}
+
+// step2 - maps double suffixes to single ones. e.g. -ization (-ize + -ation) maps to -ize.
+func (z *porterStemmer) step2() {
+	if z.k < 1 {
+		return
+	}
+	for _, p := range [][2]string{
+		{"ational", "ate"}, {"tional", "tion"},
+		{"enci", "ence"}, {"anci", "ance"},
+		{"izer", "ize"},
+		{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"},
+		{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"},
+		{"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"},
+		{"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+		{"logi", "log"},
+	} {
+		if z.ends(p[0]) {
+			z.r(p[1])
+			return
+		}
+	}
+}
+
+// step3 - deals with -ic-, -full, -ness etc.
+func (z *porterStemmer) step3() {
+	for _, p := range [][2]string{
+		{"icate", "ic"}, {"ative", ""}, {"alize", "al"},
+		{"iciti", "ic"}, {"ical", "ic"},
+		{"ful", ""}, {"ness", ""},
+	} {
+		if z.ends(p[0]) {
+			z.r(p[1])
+			return
+		}
+	}
+}
+
+// step4 - takes off -ant, -ence etc. in context <c>vcvc<v>.
+func (z *porterStemmer) step4() {
+	if z.k < 1 {
+		return
+	}
+	found := false
+	for _, s := range []string{
+		"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment", "ent",
+		"ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+	} {
+		if z.ends(s) {
+			if s == "ion" && (z.j < 0 || (z.b[z.j] != 's' && z.b[z.j] != 't')) {
+				return
+			}
+			found = true
+			break
+		}
+	}
+	if found && z.m() > 1 {
+		z.k = z.j
+	}
+}
+
+// step5 - removes a final -e if m() > 1, and changes -ll to -l if m() > 1.
+func (z *porterStemmer) step5() {
+	z.j = z.k
+	if z.b[z.k] == 'e' {
+		a := z.m()
+		if a > 1 || a == 1 && !z.cvc(z.k-1) {
+			z.k--
+		}
+	}
+	if z.b[z.k] == 'l' && z.doublec(z.k) && z.m() > 1 {
+		z.k--
+	}
+}
@@ -0,0 +1,76 @@
+package xim
+
+import (
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	cases := map[string]string{
+		"caresses":        "caress",
+		"ponies":          "poni",
+		"ties":            "ti",
+		"caress":          "caress",
+		"cats":            "cat",
+		"feed":            "feed",
+		"agreed":          "agre",
+		"plastered":       "plaster",
+		"bled":            "bled",
+		"motoring":        "motor",
+		"sing":            "sing",
+		"conflated":       "conflat",
+		"troubled":        "troubl",
+		"sized":           "size",
+		"hopping":         "hop",
+		"tanned":          "tan",
+		"falling":         "fall",
+		"hissing":         "hiss",
+		"fizzed":          "fizz",
+		"failing":         "fail",
+		"filing":          "file",
+		"happy":           "happi",
+		"sky":             "sky",
+		"relational":      "relat",
+		"conditional":     "condit",
+		"rational":        "ration",
+		"digitizer":       "digit",
+		"vietnamization":  "vietnam",
+		"hopefulness":     "hope",
+		"goodness":        "good",
+		"allowance":       "allow",
+		"adjustable":      "adjust",
+		"replacement":     "replac",
+		"adoption":        "adopt",
+		"generalizations": "gener",
+		"oscillators":     "oscil",
+		"controll":        "control",
+		"running":         "run",
+		"shoes":           "shoe",
+		"Running":         "run",
+		"Happy":           "happi",
+		"FILING":          "file",
+		"FILES":           "file",
+		"\u212Aings":      "king",
+		"a":               "a",
+		"café":            "café",
+		"x1":              "x1",
+	}
+
+	for word, expected := range cases {
+		if actual := Stem(word); actual != expected {
+			t.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", word, actual, expected)
+		}
+	}
+}
+
+func TestIsStopword(t *testing.T) {
+	for _, word := range []string{"the", "The", "and", "with"} {
+		if !IsStopword(word) {
+			t.Errorf("%s: expected: stopword, but was: not stopword", word)
+		}
+	}
+	for _, word := range []string{"shoe", "run", ""} {
+		if IsStopword(word) {
+			t.Errorf("%s: expected: not stopword, but was: stopword", word)
+		}
+	}
+}
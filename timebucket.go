@@ -0,0 +1,213 @@
+package xim
+
+import (
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// TimeGranularity - describes a calendar bucket of time.
+type TimeGranularity int
+
+const (
+	TimeYear  TimeGranularity = iota + 1 // e.g. "2006"
+	TimeMonth                            // e.g. "2006-01"
+	TimeDate                             // e.g. "2006-01-02"
+	TimeHour                             // e.g. "2006-01-02T15"
+)
+
+func (g TimeGranularity) valid() bool {
+	return TimeYear <= g && g <= TimeHour
+}
+
+func (g TimeGranularity) layout() string {
+	switch g {
+	case TimeYear:
+		return "2006"
+	case TimeMonth:
+		return "2006-01"
+	case TimeDate:
+		return "2006-01-02"
+	}
+	return "2006-01-02T15"
+}
+
+// floor - returns the start of the bucket containing t.
+func (g TimeGranularity) floor(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch g {
+	case TimeYear:
+		return startOfDay(y, 1, 1, t.Location())
+	case TimeMonth:
+		return startOfDay(y, m, 1, t.Location())
+	case TimeDate:
+		return startOfDay(y, m, d, t.Location())
+	}
+	// Truncate doesn't work for locations whose offsets are not whole hours
+	elapsed := time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second +
+		time.Duration(t.Nanosecond())
+	return t.Add(-elapsed)
+}
+
+// next - returns the start of the next bucket of the bucket starting at t.
+func (g TimeGranularity) next(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch g {
+	case TimeYear:
+		return startOfDay(y+1, 1, 1, t.Location())
+	case TimeMonth:
+		return startOfDay(y, m+1, 1, t.Location())
+	case TimeDate:
+		return startOfDay(y, m, d+1, t.Location())
+	}
+	return t.Add(time.Hour)
+}
+
+// startOfDay - returns the first instant of the date in loc.
+// time.Date can return an instant of the previous date if midnight is skipped by a DST transition,
+// so the transition is searched in that case.
+func startOfDay(y int, m time.Month, d int, loc *time.Location) time.Time {
+	// normalize the date such as the 32nd of a month
+	y, m, d = time.Date(y, m, d, 12, 0, 0, 0, loc).Date()
+	isBefore := func(t time.Time) bool {
+		ty, tm, td := t.Date()
+		return ty < y || ty == y && (tm < m || tm == m && td < d)
+	}
+
+	t := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	if !isBefore(t) {
+		return t
+	}
+	lo, hi := t, t.Add(24*time.Hour)
+	for hi.Sub(lo) > time.Nanosecond {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if isBefore(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+// ceil - returns the start of the first bucket at t or after t.
+func (g TimeGranularity) ceil(t time.Time) time.Time {
+	if f := g.floor(t); !f.Equal(t) {
+		return g.next(f)
+	}
+	return t
+}
+
+// TimeBucketSpec - describes calendar buckets of time for range search.
+type TimeBucketSpec struct {
+	Granularity TimeGranularity // the finest buckets to index
+	Location    *time.Location  // location of the calendar. nil means UTC
+}
+
+// Validate - validates the spec.
+func (spec TimeBucketSpec) Validate() error {
+	if !spec.Granularity.valid() {
+		return xerrors.Errorf("unknown Granularity of TimeBucketSpec: %d", spec.Granularity)
+	}
+	return nil
+}
+
+func (spec TimeBucketSpec) mustValidate() {
+	if err := spec.Validate(); err != nil {
+		panic(err)
+	}
+}
+
+func (spec TimeBucketSpec) location() *time.Location {
+	if spec.Location == nil {
+		return time.UTC
+	}
+	return spec.Location
+}
+
+// TimeBucketTokens - returns calendar bucket tokens of t from a year to the granularity of the spec.
+// e.g. "2006", "2006-01" and "2006-01-02" for TimeDate.
+func TimeBucketTokens(t time.Time, spec TimeBucketSpec) []string {
+	spec.mustValidate()
+
+	t = t.In(spec.location())
+	tokens := make([]string, 0, int(spec.Granularity))
+	for g := TimeYear; g <= spec.Granularity; g++ {
+		tokens = append(tokens, t.Format(g.layout()))
+	}
+	return tokens
+}
+
+// timeBucket - a calendar bucket starting at start.
+type timeBucket struct {
+	start       time.Time
+	granularity TimeGranularity
+}
+
+func (b timeBucket) token() string {
+	return b.start.Format(b.granularity.layout())
+}
+
+// repeated - reports whether the hour of the bucket is repeated by a DST transition.
+// Tokens have no offsets, so the token of the bucket also matches times in the other hour.
+func (b timeBucket) repeated() bool {
+	if b.granularity != TimeHour {
+		return false
+	}
+	token := b.token()
+	return b.start.Add(-time.Hour).Format(TimeHour.layout()) == token ||
+		b.start.Add(time.Hour).Format(TimeHour.layout()) == token
+}
+
+// timeBuckets - returns the fewest buckets covering [start, end) with buckets of finest or coarser.
+// The range is widened to the boundaries of the finest buckets.
+func timeBuckets(start, end time.Time, finest TimeGranularity) []timeBucket {
+	buckets := make([]timeBucket, 0, 32)
+	end = finest.ceil(end)
+	for cur := finest.floor(start); cur.Before(end); {
+		prev := cur
+		for g := TimeYear; g <= finest; g++ {
+			if next := g.next(cur); g.floor(cur).Equal(cur) && !next.After(end) {
+				buckets = append(buckets, timeBucket{start: cur, granularity: g})
+				cur = next
+				break
+			}
+		}
+		if !cur.After(prev) {
+			// unreachable unless the location has unexpected transitions, but never loop forever
+			buckets = append(buckets, timeBucket{start: cur, granularity: finest})
+			if cur = finest.next(finest.floor(cur)); !cur.After(prev) {
+				break
+			}
+		}
+	}
+	return buckets
+}
+
+// timeRangeFilterOptions - returns alternative tokens for [start, end).
+// Buckets are coarsened if there are too many, and exact reports whether they cover the range exactly.
+// It returns no options if even buckets by years are more than MaxFilterAlternatives.
+func timeRangeFilterOptions(start, end time.Time, spec TimeBucketSpec) (options [][]string, exact bool) {
+	spec.mustValidate()
+
+	if !start.Before(end) {
+		return [][]string{{rangeNone}}, true
+	}
+
+	start, end = start.In(spec.location()), end.In(spec.location())
+	for finest := spec.Granularity; finest >= TimeYear; finest-- {
+		buckets := timeBuckets(start, end, finest)
+		if len(buckets) > MaxFilterAlternatives {
+			continue
+		}
+
+		exact = finest == spec.Granularity && finest.floor(start).Equal(start) && finest.floor(end).Equal(end)
+		options = make([][]string, 0, len(buckets))
+		for _, b := range buckets {
+			options = append(options, []string{b.token()})
+			exact = exact && !b.repeated()
+		}
+		return options, exact
+	}
+	return nil, false
+}
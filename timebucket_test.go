@@ -0,0 +1,191 @@
+package xim
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTimeBucketTokens(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	at := time.Date(2020, 12, 31, 15, 4, 5, 0, time.UTC)
+
+	actual := TimeBucketTokens(at, TimeBucketSpec{Granularity: TimeHour, Location: jst})
+	expected := []string{"2021", "2021-01", "2021-01-01", "2021-01-01T00"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+	}
+
+	actual = TimeBucketTokens(at, TimeBucketSpec{Granularity: TimeMonth})
+	expected = []string{"2020", "2020-12"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+	}
+}
+
+func TestTimeRangeFilterOptions(t *testing.T) {
+	tokensOf := func(options [][]string) []string {
+		tokens := make([]string, 0, len(options))
+		for _, o := range options {
+			tokens = append(tokens, o...)
+		}
+		sort.Strings(tokens)
+		return tokens
+	}
+	date := func(y int, m time.Month, d, h, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name        string
+		start, end  time.Time
+		granularity TimeGranularity
+		expected    []string
+		exact       bool
+	}{
+		{
+			name:        "month",
+			start:       date(2020, 1, 1, 0, 0),
+			end:         date(2020, 2, 1, 0, 0),
+			granularity: TimeDate,
+			expected:    []string{"2020-01"},
+			exact:       true,
+		},
+		{
+			name:        "dates across months",
+			start:       date(2020, 1, 30, 0, 0),
+			end:         date(2020, 3, 3, 0, 0),
+			granularity: TimeDate,
+			expected:    []string{"2020-01-30", "2020-01-31", "2020-02", "2020-03-01", "2020-03-02"},
+			exact:       true,
+		},
+		{
+			name:        "years and hours",
+			start:       date(2019, 12, 31, 22, 0),
+			end:         date(2021, 1, 1, 2, 0),
+			granularity: TimeHour,
+			expected:    []string{"2019-12-31T22", "2019-12-31T23", "2020", "2021-01-01T00", "2021-01-01T01"},
+			exact:       true,
+		},
+		{
+			name:        "not aligned",
+			start:       date(2020, 1, 1, 0, 30),
+			end:         date(2020, 1, 1, 2, 0),
+			granularity: TimeHour,
+			expected:    []string{"2020-01-01T00", "2020-01-01T01"},
+			exact:       false,
+		},
+		{
+			name:        "coarsened",
+			start:       date(2020, 1, 1, 1, 0),
+			end:         date(2020, 12, 31, 23, 0),
+			granularity: TimeHour,
+			expected:    []string{"2020"},
+			exact:       false,
+		},
+		{
+			name:        "more years than alternatives",
+			start:       date(1990, 1, 1, 0, 0),
+			end:         date(2030, 1, 1, 0, 0),
+			granularity: TimeYear,
+			expected:    []string{},
+			exact:       false,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			options, exact := timeRangeFilterOptions(c.start, c.end, TimeBucketSpec{Granularity: c.granularity})
+			if actual := tokensOf(options); !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, c.expected)
+			}
+			if exact != c.exact {
+				t.Errorf("exact expected: %v, but was: %v", c.exact, exact)
+			}
+		})
+	}
+
+	t.Run("location with a half-hour offset", func(t *testing.T) {
+		ist := time.FixedZone("IST", 5*60*60+30*60)
+		start := time.Date(2020, 1, 1, 10, 0, 0, 0, ist)
+		spec := TimeBucketSpec{Granularity: TimeHour, Location: ist}
+		options, exact := timeRangeFilterOptions(start, start.Add(2*time.Hour), spec)
+		expected := []string{"2020-01-01T10", "2020-01-01T11"}
+		if actual := tokensOf(options); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+		if !exact {
+			t.Errorf("expected: exact, but was: not exact")
+		}
+	})
+
+	t.Run("hour repeated by DST", func(t *testing.T) {
+		// clocks went back from 02:00 EDT to 01:00 EST on 2020-11-01 in New York, so 01:00 was repeated
+		newYork, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skip(err)
+		}
+		spec := TimeBucketSpec{Granularity: TimeHour, Location: newYork}
+		start := time.Date(2020, 11, 1, 5, 0, 0, 0, time.UTC) // 01:00 EDT
+		options, exact := timeRangeFilterOptions(start, start.Add(time.Hour), spec)
+		expected := []string{"2020-11-01T01"}
+		if actual := tokensOf(options); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+		if exact {
+			t.Errorf("expected: not exact, but was: exact")
+		}
+
+		filter := NewFilters(nil).AddTimeRange("label1", start, start.Add(time.Hour), spec)
+		if actual := filter.Matcher().Match(map[string]interface{}{"label1": start.Add(90 * time.Minute)}); actual {
+			t.Errorf("01:30 EST expected: not matched, but was: matched")
+		}
+
+		// the hour before is not repeated
+		if _, exact := timeRangeFilterOptions(start.Add(-time.Hour), start, spec); !exact {
+			t.Errorf("expected: exact, but was: not exact")
+		}
+	})
+}
+
+func TestTimeBucketsAtMidnightDST(t *testing.T) {
+	// DST started at midnight on 2018-11-04 in São Paulo, so the date started at 01:00
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip(err)
+	}
+	spec := TimeBucketSpec{Granularity: TimeDate, Location: saoPaulo}
+
+	t.Run("dates", func(t *testing.T) {
+		start := time.Date(2018, 11, 3, 12, 0, 0, 0, saoPaulo)
+		options, exact := timeRangeFilterOptions(start, start.Add(72*time.Hour), spec)
+
+		expected := [][]string{{"2018-11-03"}, {"2018-11-04"}, {"2018-11-05"}, {"2018-11-06"}}
+		if !reflect.DeepEqual(options, expected) || exact {
+			t.Errorf("unexpected, actual: `%v`, %v, expected: `%v`, false", options, exact, expected)
+		}
+	})
+
+	t.Run("hours", func(t *testing.T) {
+		start := time.Date(2018, 11, 3, 22, 0, 0, 0, saoPaulo)
+		buckets := timeBuckets(start, start.Add(4*time.Hour), TimeHour)
+
+		actual := make([]string, 0, len(buckets))
+		for _, b := range buckets {
+			actual = append(actual, b.token())
+		}
+		expected := []string{"2018-11-03T22", "2018-11-03T23", "2018-11-04T01", "2018-11-04T02"}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+	})
+
+	t.Run("start of the date", func(t *testing.T) {
+		actual := TimeDate.floor(time.Date(2018, 11, 4, 12, 0, 0, 0, saoPaulo))
+		if expected := time.Date(2018, 11, 4, 1, 0, 0, 0, saoPaulo); !actual.Equal(expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+	})
+}
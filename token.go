@@ -16,13 +16,17 @@ func (b *bigram) String() string {
 
 // Biunigrams - returns bigram and unigram tokens from s.
 func Biunigrams(s string) []string {
+	return biunigrams(s, isDefaultSeparator)
+}
+
+func biunigrams(s string, isSeparator func(rune) bool) []string {
 	tokens := make([]string, 0, 32)
 
-	for bigram := range toBigrams(s) {
+	for bigram := range toBigrams(s, isSeparator) {
 		tokens = append(tokens, fmt.Sprintf("%c%c", bigram.a, bigram.b))
 	}
 
-	for unigram := range toUnigrams(s) {
+	for unigram := range toUnigrams(s, isSeparator) {
 		tokens = append(tokens, fmt.Sprintf("%c", unigram))
 	}
 
@@ -31,33 +35,215 @@ func Biunigrams(s string) []string {
 
 // Bigrams returns bigram tokens from s.
 func Bigrams(s string) []string {
+	return bigrams(s, isDefaultSeparator)
+}
+
+func bigrams(s string, isSeparator func(rune) bool) []string {
 	tokens := make([]string, 0, 32)
 
-	for bigram := range toBigrams(s) {
+	for bigram := range toBigrams(s, isSeparator) {
 		tokens = append(tokens, fmt.Sprintf("%c%c", bigram.a, bigram.b))
 	}
 
 	return tokens
 }
 
+// Ngrams - returns n-gram tokens from s.
+// Grams never span a space, and words shorter than n produce no tokens.
+func Ngrams(s string, n int) []string {
+	return ngrams(s, n, isDefaultSeparator)
+}
+
+func ngrams(s string, n int, isSeparator func(rune) bool) []string {
+	tokens := make([]string, 0, 32)
+
+	for ngram := range toNgrams(s, n, isSeparator) {
+		tokens = append(tokens, ngram)
+	}
+
+	return tokens
+}
+
+// LengthBounds - describes the minimum and maximum length of tokens in characters.
+// Zero means no bound.
+type LengthBounds struct {
+	Min int
+	Max int
+}
+
+func (b LengthBounds) valid() bool {
+	return b.Min >= 0 && b.Max >= 0 && (b.Max == 0 || b.Min <= b.Max)
+}
+
+// exceeds - reports whether l exceeds the maximum length.
+func (b LengthBounds) exceeds(l int) bool {
+	return b.Max > 0 && l > b.Max
+}
+
 // Prefixes - returns prefix tokens from s.
 func Prefixes(s string) []string {
-	return tokenize(s, false)
+	return tokenize(s, false, isDefaultSeparator, LengthBounds{})
 }
 
 // Suffixes - returns suffix tokens from s.
 func Suffixes(s string) []string {
-	return tokenize(s, true)
+	return tokenize(s, true, isDefaultSeparator, LengthBounds{})
+}
+
+// Shingles - returns word n-gram tokens from s, which are size words joined with a space.
+// Texts shorter than size words produce no tokens.
+func Shingles(s string, size int) []string {
+	return shingles(strings.FieldsFunc(s, isDefaultSeparator), size)
 }
 
-func tokenize(s string, isSuffix bool) []string {
+func shingles(words []string, size int) []string {
 	tokenMap := make(map[string]struct{})
-	runes := make([]rune, 0, 64)
-	for _, w := range strings.Split(s, " ") {
-		if w == "" {
+	if size > 0 {
+		for i := 0; i+size <= len(words); i++ {
+			tokenMap[strings.Join(words[i:i+size], " ")] = struct{}{}
+		}
+	}
+	return tokenSlice(tokenMap)
+}
+
+// Stems - returns stem tokens of English words in s except for stopwords.
+// e.g. "running shoes" and "run shoe" have the same tokens.
+func Stems(s string) []string {
+	return stems(s, isDefaultSeparator)
+}
+
+func stems(s string, isSeparator func(rune) bool) []string {
+	tokenMap := make(map[string]struct{})
+	for _, w := range stemWords(s, isSeparator) {
+		if IsStopword(w) {
+			continue
+		}
+		tokenMap[Stem(w)] = struct{}{}
+	}
+	return tokenSlice(tokenMap)
+}
+
+// deletionDistance2MinLength - minimum length of words deleted two characters.
+// Shorter words are deleted one character at most since they would match too many words.
+const deletionDistance2MinLength = 5
+
+// Deletions - returns deletion variants of words in s, which are words with up to distance characters removed.
+// distance is 1 or 2, and 2 is applied to words of 5 characters or more only.
+// Words within the edit distance share a variant. e.g. "jonson" and "johnson" share "jonson".
+func Deletions(s string, distance int) []string {
+	tokenMap := make(map[string]struct{})
+	for _, w := range strings.FieldsFunc(s, isDefaultSeparator) {
+		for _, t := range deletions(runeUnits(w), distance) {
+			tokenMap[t] = struct{}{}
+		}
+	}
+	return tokenSlice(tokenMap)
+}
+
+// deletionDistance - returns the edit distance applied to a word of the length.
+func deletionDistance(length int, distance int) int {
+	if distance > 2 {
+		distance = 2
+	}
+	if distance > 1 && length < deletionDistance2MinLength {
+		distance = 1
+	}
+	return distance
+}
+
+// deletions - returns the word and its deletion variants.
+// chars is the word split into characters, runes or grapheme clusters.
+func deletions(chars []string, distance int) []string {
+	distance = deletionDistance(len(chars), distance)
+	if len(chars) == 0 || distance <= 0 {
+		return nil
+	}
+
+	tokenMap := map[string]struct{}{strings.Join(chars, ""): {}}
+	variants := [][]string{chars}
+	for d := 0; d < distance; d++ {
+		next := make([][]string, 0, len(variants)*len(chars))
+		for _, v := range variants {
+			if len(v) <= 1 {
+				continue
+			}
+			for i := range v {
+				deleted := make([]string, 0, len(v)-1)
+				deleted = append(append(deleted, v[:i]...), v[i+1:]...)
+				token := strings.Join(deleted, "")
+				if _, ok := tokenMap[token]; ok {
+					continue
+				}
+				tokenMap[token] = struct{}{}
+				next = append(next, deleted)
+			}
+		}
+		variants = next
+	}
+	return tokenSlice(tokenMap)
+}
+
+// PhrasePrefixes - returns prefix tokens of the whole s.
+// Unlike Prefixes, tokens span words keeping their order, and consecutive white spaces are folded into a space.
+func PhrasePrefixes(s string) []string {
+	return tokenizePhrase(s, false, runeUnits, LengthBounds{})
+}
+
+// PhraseSuffixes - returns suffix tokens of the whole s.
+// Unlike Suffixes, tokens span words keeping their order, and consecutive white spaces are folded into a space.
+func PhraseSuffixes(s string) []string {
+	return tokenizePhrase(s, true, runeUnits, LengthBounds{})
+}
+
+// normalizePhrase - trims s and folds consecutive white spaces into a space.
+func normalizePhrase(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// tokenizePhrase - returns prefix or suffix tokens of the whole s within bounds.
+// units splits s into characters, runes or grapheme clusters.
+// Tokens starting or ending with a space are skipped since queries are trimmed.
+func tokenizePhrase(s string, isSuffix bool, units func(string) []string, bounds LengthBounds) []string {
+	chars := units(normalizePhrase(s))
+	if len(chars) == 0 {
+		return nil
+	}
+	if len(chars) < bounds.Min {
+		return []string{strings.Join(chars, "")}
+	}
+
+	tokens := make([]string, 0, len(chars))
+	for i := bounds.Min; i <= len(chars) && !bounds.exceeds(i); i++ {
+		if i == 0 {
+			continue
+		}
+		token := chars[:i]
+		if isSuffix {
+			token = chars[len(chars)-i:]
+		}
+		if token[0] == " " || token[len(token)-1] == " " {
 			continue
 		}
+		tokens = append(tokens, strings.Join(token, ""))
+	}
+	return tokens
+}
 
+// runeUnits - splits s into runes.
+func runeUnits(s string) []string {
+	units := make([]string, 0, len(s))
+	for _, r := range s {
+		units = append(units, string(r))
+	}
+	return units
+}
+
+// tokenize - returns prefix or suffix tokens of each word within bounds.
+// Words shorter than the minimum length are returned as is.
+func tokenize(s string, isSuffix bool, isSeparator func(rune) bool, bounds LengthBounds) []string {
+	tokenMap := make(map[string]struct{})
+	runes := make([]rune, 0, 64)
+	for _, w := range strings.FieldsFunc(s, isSeparator) {
 		if isSuffix {
 			w = reverse(w)
 		}
@@ -66,6 +252,15 @@ func tokenize(s string, isSuffix bool) []string {
 
 		for _, c := range w {
 			runes = append(runes, c)
+			if bounds.exceeds(len(runes)) {
+				break
+			}
+			if len(runes) >= bounds.Min {
+				tokenMap[string(runes)] = struct{}{}
+			}
+		}
+
+		if len(runes) < bounds.Min {
 			tokenMap[string(runes)] = struct{}{}
 		}
 	}
@@ -90,11 +285,23 @@ func reverse(s string) string {
 	return string(runes)
 }
 
-func toBigrams(value string) map[bigram]struct{} {
+// truncate - returns the first or last n runes of s.
+func truncate(s string, n int, fromEnd bool) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if fromEnd {
+		return string(runes[len(runes)-n:])
+	}
+	return string(runes[:n])
+}
+
+func toBigrams(value string, isSeparator func(rune) bool) map[bigram]struct{} {
 	result := make(map[bigram]struct{})
 	var prev rune
 	for i, r := range value {
-		if i > 0 && prev != ' ' && r != ' ' {
+		if i > 0 && !isSeparator(prev) && !isSeparator(r) {
 			result[bigram{prev, r}] = struct{}{}
 		}
 		prev = r
@@ -102,10 +309,51 @@ func toBigrams(value string) map[bigram]struct{} {
 	return result
 }
 
-func toUnigrams(value string) map[rune]struct{} {
+func toNgrams(value string, n int, isSeparator func(rune) bool) map[string]struct{} {
+	result := make(map[string]struct{})
+	if n <= 0 {
+		return result
+	}
+	for _, w := range strings.FieldsFunc(value, isSeparator) {
+		runes := []rune(w)
+		for i := 0; i+n <= len(runes); i++ {
+			result[string(runes[i:i+n])] = struct{}{}
+		}
+	}
+	return result
+}
+
+// queryNgrams - returns n-gram tokens for searching s.
+// Words shorter than n are used as is so that they match the shorter grams saved by Indexes.AddNgrams.
+// ngrams and length split words into characters, runes or grapheme clusters.
+func queryNgrams(s string, n int, ngrams func(string, int) []string, length func(string) int,
+	isSeparator func(rune) bool) []string {
+	tokenMap := make(map[string]struct{})
+	for _, w := range strings.FieldsFunc(s, isSeparator) {
+		if length(w) < n {
+			tokenMap[w] = struct{}{}
+			continue
+		}
+		for _, ngram := range ngrams(w, n) {
+			tokenMap[ngram] = struct{}{}
+		}
+	}
+
+	return tokenSlice(tokenMap)
+}
+
+func tokenSlice(tokenMap map[string]struct{}) []string {
+	tokens := make([]string, 0, len(tokenMap))
+	for t := range tokenMap {
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
+
+func toUnigrams(value string, isSeparator func(rune) bool) map[rune]struct{} {
 	result := make(map[rune]struct{})
 	for _, r := range value {
-		if r == ' ' {
+		if isSeparator(r) {
 			continue
 		}
 		result[r] = struct{}{}
@@ -2,6 +2,7 @@ package xim
 
 import (
 	"fmt"
+	"reflect"
 	"sort"
 	"testing"
 )
@@ -14,7 +15,7 @@ func TestString(t *testing.T) {
 }
 
 func TestToUnigrams(t *testing.T) {
-	result := toUnigrams("abc dあいbCh")
+	result := toUnigrams("abc dあいbCh", isDefaultSeparator)
 	if len(result) != 8 {
 		t.Errorf("len(result) exected:%d, but was: %d\n", 8, len(result))
 	}
@@ -41,7 +42,7 @@ func TestToUnigrams(t *testing.T) {
 }
 
 func TestToBigrams(t *testing.T) {
-	result := toBigrams("abc debch iJあdeN")
+	result := toBigrams("abc debch iJあdeN", isDefaultSeparator)
 	if len(result) != 9 {
 		t.Errorf("len(result) exected:%d, but was: %d\n", 9, len(result))
 	}
@@ -191,3 +192,152 @@ func TestSuffixes(t *testing.T) {
 		})
 	}
 }
+
+func TestNgrams(t *testing.T) {
+	result := Ngrams("abcd dあいbC h", 3)
+	if len(result) != 5 {
+		t.Errorf("len(result) exected:%d, but was: %d\n", 5, len(result))
+	}
+
+	sort.Strings(result)
+
+	expected := []string{
+		"abc",
+		"bcd",
+		"dあい",
+		"あいb",
+		"いbC",
+	}
+
+	for i := range result {
+		i := i // escape: Using the variable on range scope `i` in loop literal
+		t.Run(fmt.Sprintf("result[%d]", i), func(tr *testing.T) {
+			if result[i] != expected[i] {
+				tr.Errorf("%s: unexpected, actual: `%v`, expected: `%v`", t.Name(), result[i], expected[i])
+			}
+		})
+	}
+
+	if result := Ngrams("abc", 0); len(result) != 0 {
+		t.Errorf("len(result) exected:%d, but was: %d\n", 0, len(result))
+	}
+}
+
+func TestTokenizeWithinBounds(t *testing.T) {
+	cases := []struct {
+		name     string
+		isSuffix bool
+		bounds   LengthBounds
+		expected []string
+	}{
+		{"prefixes max", false, LengthBounds{Max: 3}, []string{"a", "ab", "abc", "d", "dあ", "dあい"}},
+		{"prefixes min", false, LengthBounds{Min: 4}, []string{"abc", "dあいb", "dあいbC", "dあいbCh"}},
+		{"prefixes min and max", false, LengthBounds{Min: 2, Max: 4}, []string{"ab", "abc", "dあ", "dあい", "dあいb"}},
+		{"suffixes max", true, LengthBounds{Max: 2}, []string{"Ch", "bc", "c", "h"}},
+		{"suffixes min and max", true, LengthBounds{Min: 3, Max: 4}, []string{"abc", "bCh", "いbCh"}},
+	}
+
+	for _, c := range cases {
+		c := c // escape: Using the variable on range scope `c` in loop literal
+		t.Run(c.name, func(t *testing.T) {
+			result := tokenize("abc dあいbCh", c.isSuffix, isDefaultSeparator, c.bounds)
+			sort.Strings(result)
+			sort.Strings(c.expected)
+			if !reflect.DeepEqual(result, c.expected) {
+				t.Errorf("unexpected, actual: `%v`, expected: `%v`", result, c.expected)
+			}
+		})
+	}
+}
+
+func TestPhrasePrefixes(t *testing.T) {
+	result := PhrasePrefixes("  ab \t c　 ")
+	sort.Strings(result)
+
+	expected := []string{"a", "ab", "ab c"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected, actual: `%q`, expected: `%q`", result, expected)
+	}
+}
+
+func TestPhraseSuffixes(t *testing.T) {
+	result := PhraseSuffixes("ab  cd")
+	sort.Strings(result)
+
+	expected := []string{"ab cd", "b cd", "cd", "d"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected, actual: `%q`, expected: `%q`", result, expected)
+	}
+}
+
+func TestTokenizePhraseWithinBounds(t *testing.T) {
+	result := tokenizePhrase("harry potter", false, runeUnits, LengthBounds{Min: 3, Max: 8})
+	sort.Strings(result)
+
+	expected := []string{"har", "harr", "harry", "harry p", "harry po"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected, actual: `%q`, expected: `%q`", result, expected)
+	}
+}
+
+func TestShingles(t *testing.T) {
+	result := Shingles("new york  pizza new york", 2)
+	sort.Strings(result)
+
+	expected := []string{"new york", "pizza new", "york pizza"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected, actual: `%q`, expected: `%q`", result, expected)
+	}
+
+	if result := Shingles("new york", 3); len(result) != 0 {
+		t.Errorf("len(result) exected:%d, but was: %d\n", 0, len(result))
+	}
+}
+
+func TestStems(t *testing.T) {
+	result := Stems("The running shoes, for runners' feet and the runner's shoe")
+	sort.Strings(result)
+
+	expected := []string{"feet", "run", "runner", "shoe"}
+	sort.Strings(expected)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected, actual: `%q`, expected: `%q`", result, expected)
+	}
+}
+
+func TestDeletions(t *testing.T) {
+	t.Run("distance 1", func(t *testing.T) {
+		actual := Deletions("abc", 1)
+		sort.Strings(actual)
+		expected := []string{"ab", "abc", "ac", "bc"}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+	})
+
+	t.Run("distance 2 for short words", func(t *testing.T) {
+		actual := Deletions("abc", 2)
+		sort.Strings(actual)
+		expected := []string{"ab", "abc", "ac", "bc"}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+	})
+
+	t.Run("distance 2", func(t *testing.T) {
+		actual := Deletions("abcde", 2)
+		// the word, 5 deletions of a character and 10 deletions of 2 characters
+		if len(actual) != 16 {
+			t.Errorf("len(actual) expected: %d, but was: %d", 16, len(actual))
+		}
+	})
+
+	t.Run("repeated characters", func(t *testing.T) {
+		actual := Deletions("aab あ", 1)
+		sort.Strings(actual)
+		expected := []string{"aa", "aab", "ab", "あ"}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+	})
+}
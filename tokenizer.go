@@ -0,0 +1,304 @@
+package xim
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// Tokenizer - describes how to split a value into index tokens and filter tokens.
+type Tokenizer interface {
+	IndexTokens(s string) []string  // returns tokens to save as indexes
+	FilterTokens(s string) []string // returns tokens to search indexes saved by IndexTokens
+}
+
+// TokenizerFuncs - adapter to use ordinary functions as Tokenizer.
+// Index is used for both sides if Filter is nil.
+type TokenizerFuncs struct {
+	Index  func(s string) []string
+	Filter func(s string) []string
+}
+
+// IndexTokens - returns tokens to save as indexes.
+func (f TokenizerFuncs) IndexTokens(s string) []string {
+	return f.Index(s)
+}
+
+// FilterTokens - returns tokens to search.
+func (f TokenizerFuncs) FilterTokens(s string) []string {
+	if f.Filter == nil {
+		return f.Index(s)
+	}
+	return f.Filter(s)
+}
+
+// names of built-in tokenizers.
+const (
+	TokenizerBigrams         = "bigrams"
+	TokenizerBiunigrams      = "biunigrams"
+	TokenizerTrigrams        = "trigrams"
+	TokenizerPrefixes        = "prefixes"
+	TokenizerSuffixes        = "suffixes"
+	TokenizerStems           = "stems"
+	TokenizerSoundex         = "soundex"
+	TokenizerMetaphone       = "metaphone"
+	TokenizerDoubleMetaphone = "doublemetaphone"
+)
+
+// NewNgramTokenizer - creates a Tokenizer which works as Indexes.AddNgrams and Filters.AddNgrams.
+func NewNgramTokenizer(n int) Tokenizer {
+	return runeTokenizers.ngrams(n)
+}
+
+// built-in tokenizers switched by Config.
+type builtinTokenizers struct {
+	bigrams    Tokenizer
+	biunigrams Tokenizer
+	prefixes   func(bounds LengthBounds) Tokenizer
+	suffixes   func(bounds LengthBounds) Tokenizer
+	phrases    func(isSuffix bool, bounds LengthBounds) Tokenizer
+	shingles   func(size int) Tokenizer
+	stems      Tokenizer
+	phonetic   func(p Phonetic) Tokenizer
+	deletions  func(distance int) Tokenizer
+	ngrams     func(n int) Tokenizer
+	length     func(s string) int      // counts characters, runes or grapheme clusters
+	units      func(s string) []string // splits s into characters, runes or grapheme clusters
+	words      func(s string) []string // splits words
+}
+
+func newBuiltinTokenizers(graphemes bool, isSeparator func(rune) bool) *builtinTokenizers {
+	// split words into characters
+	ngramsOf := func(s string, n int) []string {
+		return ngrams(s, n, isSeparator)
+	}
+	length := utf8.RuneCountInString
+	affixesOf := func(s string, isSuffix bool, bounds LengthBounds) []string {
+		return tokenize(s, isSuffix, isSeparator, bounds)
+	}
+	truncateOf := truncate
+	unitsOf := runeUnits
+	bigramsOf := func(s string) []string {
+		return bigrams(s, isSeparator)
+	}
+	biunigramsOf := func(s string) []string {
+		return biunigrams(s, isSeparator)
+	}
+	if graphemes {
+		ngramsOf = func(s string, n int) []string {
+			return graphemeNgrams(s, n, isSeparator)
+		}
+		length = graphemeLen
+		affixesOf = func(s string, isSuffix bool, bounds LengthBounds) []string {
+			return graphemeTokenize(s, isSuffix, isSeparator, bounds)
+		}
+		truncateOf = graphemeTruncate
+		unitsOf = Graphemes
+		bigramsOf = func(s string) []string {
+			return ngramsOf(s, 2)
+		}
+		biunigramsOf = func(s string) []string {
+			return append(ngramsOf(s, 2), ngramsOf(s, 1)...)
+		}
+	}
+
+	biunigramFilterTokens := func(s string) []string {
+		if l := length(s); l == 1 {
+			return []string{s}
+		} else if l > 1 {
+			return bigramsOf(s)
+		}
+		return nil
+	}
+
+	// queries longer than the maximum length are truncated since longer tokens are not saved.
+	affixTokenizer := func(isSuffix bool, bounds LengthBounds) Tokenizer {
+		return TokenizerFuncs{
+			Index: func(s string) []string {
+				return affixesOf(s, isSuffix, bounds)
+			},
+			Filter: func(s string) []string {
+				// don't need to split prefixes and suffixes on filters
+				if bounds.exceeds(length(s)) {
+					s = truncateOf(s, bounds.Max, isSuffix)
+				}
+				return []string{s}
+			},
+		}
+	}
+
+	return &builtinTokenizers{
+		bigrams: TokenizerFuncs{
+			Index:  bigramsOf,
+			Filter: biunigramFilterTokens,
+		},
+		biunigrams: TokenizerFuncs{
+			Index:  biunigramsOf,
+			Filter: biunigramFilterTokens,
+		},
+		prefixes: func(bounds LengthBounds) Tokenizer {
+			return affixTokenizer(false, bounds)
+		},
+		suffixes: func(bounds LengthBounds) Tokenizer {
+			return affixTokenizer(true, bounds)
+		},
+		phrases: func(isSuffix bool, bounds LengthBounds) Tokenizer {
+			return TokenizerFuncs{
+				Index: func(s string) []string {
+					return tokenizePhrase(s, isSuffix, unitsOf, bounds)
+				},
+				Filter: func(s string) []string {
+					s = normalizePhrase(s)
+					if bounds.exceeds(length(s)) {
+						s = truncateOf(s, bounds.Max, isSuffix)
+					}
+					return []string{s}
+				},
+			}
+		},
+		shingles: func(size int) Tokenizer {
+			return TokenizerFuncs{
+				Index: func(s string) []string {
+					words := strings.FieldsFunc(s, isSeparator)
+					tokens := make([]string, 0, 32)
+					for i := 1; i <= size; i++ {
+						tokens = append(tokens, shingles(words, i)...)
+					}
+					return tokens
+				},
+				Filter: func(s string) []string {
+					words := strings.FieldsFunc(s, isSeparator)
+					if size <= 0 || len(words) == 0 {
+						return nil
+					}
+					if len(words) < size {
+						// matches the shorter shingles saved by Indexes.AddShingles.
+						return []string{strings.Join(words, " ")}
+					}
+					return shingles(words, size)
+				},
+			}
+		},
+		ngrams: func(n int) Tokenizer {
+			return TokenizerFuncs{
+				Index: func(s string) []string {
+					tokens := make([]string, 0, 32)
+					for i := 1; i <= n; i++ {
+						tokens = append(tokens, ngramsOf(s, i)...)
+					}
+					return tokens
+				},
+				Filter: func(s string) []string {
+					if n <= 0 {
+						return nil
+					}
+					return queryNgrams(s, n, ngramsOf, length, isSeparator)
+				},
+			}
+		},
+		stems: TokenizerFuncs{
+			Index: func(s string) []string {
+				return stems(s, isSeparator)
+			},
+		},
+		phonetic: func(p Phonetic) Tokenizer {
+			return TokenizerFuncs{
+				Index: func(s string) []string {
+					return phoneticCodes(s, p, isSeparator)
+				},
+				// primary codes of queries match either code of indexes
+				Filter: func(s string) []string {
+					return phoneticPrimaryCodes(s, p, isSeparator)
+				},
+			}
+		},
+		deletions: func(distance int) Tokenizer {
+			return TokenizerFuncs{
+				Index: func(s string) []string {
+					tokens := make([]string, 0, 32)
+					for _, w := range strings.FieldsFunc(s, isSeparator) {
+						tokens = append(tokens, deletions(unitsOf(w), distance)...)
+					}
+					return tokens
+				},
+			}
+		},
+		length: length,
+		units:  unitsOf,
+		words: func(s string) []string {
+			return strings.FieldsFunc(s, isSeparator)
+		},
+	}
+}
+
+var (
+	runeTokenizers     = newBuiltinTokenizers(false, isDefaultSeparator)
+	graphemeTokenizers = newBuiltinTokenizers(true, isDefaultSeparator)
+)
+
+// tokenizers - returns built-in tokenizers for the configuration.
+func (conf *Config) tokenizers() *builtinTokenizers {
+	if conf.WordBoundary != nil {
+		return newBuiltinTokenizers(conf.Graphemes, conf.WordBoundary.isSeparator)
+	}
+	if conf.Graphemes {
+		return graphemeTokenizers
+	}
+	return runeTokenizers
+}
+
+var tokenizerRegistry = struct {
+	sync.RWMutex
+	m map[string]Tokenizer
+}{
+	m: map[string]Tokenizer{
+		TokenizerBigrams:         runeTokenizers.bigrams,
+		TokenizerBiunigrams:      runeTokenizers.biunigrams,
+		TokenizerTrigrams:        runeTokenizers.ngrams(3),
+		TokenizerPrefixes:        runeTokenizers.prefixes(LengthBounds{}),
+		TokenizerSuffixes:        runeTokenizers.suffixes(LengthBounds{}),
+		TokenizerStems:           runeTokenizers.stems,
+		TokenizerSoundex:         runeTokenizers.phonetic(PhoneticSoundex),
+		TokenizerMetaphone:       runeTokenizers.phonetic(PhoneticMetaphone),
+		TokenizerDoubleMetaphone: runeTokenizers.phonetic(PhoneticDoubleMetaphone),
+	},
+}
+
+// RegisterTokenizer - registers a Tokenizer with a name.
+// It fails if the name is empty or already registered.
+func RegisterTokenizer(name string, t Tokenizer) error {
+	if name == "" {
+		return xerrors.New("tokenizer name is empty")
+	}
+	if t == nil {
+		return xerrors.Errorf("tokenizer %q is nil", name)
+	}
+
+	tokenizerRegistry.Lock()
+	defer tokenizerRegistry.Unlock()
+
+	if _, ok := tokenizerRegistry.m[name]; ok {
+		return xerrors.Errorf("tokenizer %q is already registered", name)
+	}
+	tokenizerRegistry.m[name] = t
+
+	return nil
+}
+
+// MustRegisterTokenizer - registers a Tokenizer and panics if it fails.
+func MustRegisterTokenizer(name string, t Tokenizer) {
+	if err := RegisterTokenizer(name, t); err != nil {
+		panic(err)
+	}
+}
+
+// LookupTokenizer - returns the Tokenizer registered with the name.
+func LookupTokenizer(name string) (Tokenizer, bool) {
+	tokenizerRegistry.RLock()
+	defer tokenizerRegistry.RUnlock()
+
+	t, ok := tokenizerRegistry.m[name]
+	return t, ok
+}
@@ -0,0 +1,104 @@
+package xim
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var upperWordsTokenizer = TokenizerFuncs{
+	Index: func(s string) []string {
+		return strings.Fields(strings.ToUpper(s))
+	},
+}
+
+func TestTokenizerFuncs(t *testing.T) {
+	t.Run("Filter is nil", func(t *testing.T) {
+		actual := upperWordsTokenizer.FilterTokens("abc dあい")
+		expected := []string{"ABC", "Dあい"}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+	})
+
+	t.Run("Filter is not nil", func(t *testing.T) {
+		tokenizer := TokenizerFuncs{Index: Prefixes, Filter: func(s string) []string { return []string{s} }}
+		actual := tokenizer.FilterTokens("abc dあい")
+		expected := []string{"abc dあい"}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, expected)
+		}
+	})
+}
+
+// unregisterTokenizer - removes the tokenizer registered by tests so that they can run repeatedly.
+func unregisterTokenizer(name string) {
+	tokenizerRegistry.Lock()
+	defer tokenizerRegistry.Unlock()
+	delete(tokenizerRegistry.m, name)
+}
+
+func TestRegisterTokenizer(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		defer unregisterTokenizer("test-upper-words")
+		if err := RegisterTokenizer("test-upper-words", upperWordsTokenizer); err != nil {
+			t.Errorf("error = %s, wants = nil", err)
+		}
+
+		tokenizer, ok := LookupTokenizer("test-upper-words")
+		if !ok {
+			t.Fatal("tokenizer not found")
+		}
+		if actual := tokenizer.IndexTokens("ab"); !reflect.DeepEqual(actual, []string{"AB"}) {
+			t.Errorf("unexpected, actual: `%v`, expected: `%v`", actual, []string{"AB"})
+		}
+	})
+
+	t.Run("Already registered", func(t *testing.T) {
+		if err := RegisterTokenizer(TokenizerBigrams, upperWordsTokenizer); err == nil {
+			t.Error("error = nil, wants != nil")
+		}
+	})
+
+	t.Run("Empty name", func(t *testing.T) {
+		if err := RegisterTokenizer("", upperWordsTokenizer); err == nil {
+			t.Error("error = nil, wants != nil")
+		}
+	})
+
+	t.Run("Nil tokenizer", func(t *testing.T) {
+		if err := RegisterTokenizer("test-nil", nil); err == nil {
+			t.Error("error = nil, wants != nil")
+		}
+	})
+}
+
+func TestLookupTokenizer(t *testing.T) {
+	names := []string{TokenizerBigrams, TokenizerBiunigrams, TokenizerTrigrams, TokenizerPrefixes, TokenizerSuffixes}
+	for _, name := range names {
+		if _, ok := LookupTokenizer(name); !ok {
+			t.Errorf("tokenizer %s not found", name)
+		}
+	}
+
+	if _, ok := LookupTokenizer("unknown"); ok {
+		t.Error("unknown tokenizer found")
+	}
+}
+
+func TestAddTokenizedIndexAndFilter(t *testing.T) {
+	idx := NewIndexes(nil)
+	idx.AddTokenized("label1", upperWordsTokenizer, "abc dあいbCh")
+
+	assertBuiltIndex(t, idx.MustBuild(), map[string]bool{
+		"label1 ABC":    true,
+		"label1 DあいBCH": true,
+	})
+
+	filter := NewFilters(nil)
+	filter.AddTokenized("label1", upperWordsTokenizer, "dあいbch")
+
+	assertBuiltFilter(t, filter.MustBuild(), map[string]bool{
+		"label1 DあいBCH": true,
+	})
+}
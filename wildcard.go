@@ -0,0 +1,100 @@
+package xim
+
+// wildcard characters of patterns.
+const (
+	wildcardAny    = "*" // matches any sequence of characters including empty
+	wildcardSingle = "?" // matches a single character
+)
+
+// WildcardLabels - describes labels of a value indexed for wildcard search.
+// Empty labels are not used.
+type WildcardLabels struct {
+	Prefix  string // label of indexes saved by Indexes.AddPrefixes
+	Suffix  string // label of indexes saved by Indexes.AddSuffixes
+	Partial string // label of indexes saved by Indexes.AddBiunigrams
+}
+
+// postFilterLabel - returns the label for the PostFilter of wildcard search.
+func (labels WildcardLabels) postFilterLabel() string {
+	switch {
+	case labels.Partial != "":
+		return labels.Partial
+	case labels.Prefix != "":
+		return labels.Prefix
+	}
+	return labels.Suffix
+}
+
+// wildcardPattern - a pattern split into literal fragments.
+type wildcardPattern struct {
+	fragments [][]string // literal fragments split into characters
+	prefix    bool       // whether the first fragment is at the start of the pattern
+	suffix    bool       // whether the last fragment is at the end of the pattern
+}
+
+func parseWildcard(chars []string) wildcardPattern {
+	var p wildcardPattern
+	fragment := make([]string, 0, len(chars))
+	for i, c := range chars {
+		if c == wildcardAny || c == wildcardSingle {
+			if len(fragment) > 0 {
+				p.fragments = append(p.fragments, fragment)
+				fragment = make([]string, 0, len(chars))
+			}
+			continue
+		}
+		if i == 0 {
+			p.prefix = true
+		}
+		fragment = append(fragment, c)
+	}
+	if len(fragment) > 0 {
+		p.fragments = append(p.fragments, fragment)
+		p.suffix = true
+	}
+	return p
+}
+
+// wildcardMatch - reports whether chars match the pattern.
+// It backtracks to the last '*' on mismatch.
+func wildcardMatch(pattern, chars []string) bool {
+	p, c := 0, 0
+	star, starC := -1, 0
+	for c < len(chars) {
+		switch {
+		case p < len(pattern) && (pattern[p] == wildcardSingle || pattern[p] == chars[c]):
+			p++
+			c++
+		case p < len(pattern) && pattern[p] == wildcardAny:
+			star, starC = p, c
+			p++
+		case star >= 0:
+			starC++
+			p, c = star+1, starC
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == wildcardAny {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// WildcardMatch - reports whether any word of value matches the pattern normalized with conf.
+// '*' in the pattern matches any sequence of characters and '?' matches a single character.
+// It verifies search results of Filters.AddWildcard exactly.
+func WildcardMatch(conf *Config, pattern string, value string) bool {
+	if conf == nil {
+		conf = DefaultConfig
+	}
+	tokenizers := conf.tokenizers()
+
+	p := tokenizers.units(conf.normalize(pattern))
+	for _, w := range tokenizers.words(conf.normalize(value)) {
+		if wildcardMatch(p, tokenizers.units(w)) {
+			return true
+		}
+	}
+	return false
+}
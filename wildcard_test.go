@@ -0,0 +1,44 @@
+package xim
+
+import (
+	"testing"
+)
+
+func TestWildcardMatch(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		value    string
+		expected bool
+	}{
+		{"ab*cd", "abcd", true},
+		{"ab*cd", "abxxcd", true},
+		{"ab*cd", "abxxcde", false},
+		{"*ing", "running shoes", true},
+		{"*ing", "run", false},
+		{"ha?ry", "harry", true},
+		{"ha?ry", "hary", false},
+		{"ha?ry", "Harry", false},
+		{"*a*b*", "xaxxbx", true},
+		{"*a*b*", "xbxxax", false},
+		{"*", "anything", true},
+		{"あ?う", "あいう", true},
+		{"abc", "abc", true},
+		{"abc", "abcd", false},
+	}
+
+	for _, c := range cases {
+		if actual := WildcardMatch(nil, c.pattern, c.value); actual != c.expected {
+			t.Errorf("%s, %s: unexpected, actual: `%v`, expected: `%v`", c.pattern, c.value, actual, c.expected)
+		}
+	}
+
+	t.Run("normalized with config", func(t *testing.T) {
+		conf := &Config{IgnoreCase: true, Graphemes: true}
+		if !WildcardMatch(conf, "HA?RY", "harry") {
+			t.Errorf("expected: matched, but was: not matched")
+		}
+		if !WildcardMatch(conf, "a?b", "a👍🏻b") {
+			t.Errorf("expected: matched, but was: not matched")
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"golang.org/x/text/language"
 	"golang.org/x/xerrors"
 )
 
@@ -14,15 +15,27 @@ const (
 	IndexNoFilters          = "_NF_" // index to be used for no-filters.
 	MaxIndexesSize          = 512    // maximum size of indexes.
 	MaxCompositeIndexLabels = 8      // maximum number of labels for composite index.
+	MaxFilterAlternatives   = 30     // maximum number of filters built by Filters.BuildAll.
 )
 
 const combinationIndexSeparator = ";"
 
 // Config - describe extra indexes configuration.
 type Config struct {
-	CompositeIdxLabels []string // label list which defines composite indexes to improve the search performance
-	IgnoreCase         bool     // defines whether to ignore case on search
-	SaveNoFiltersIndex bool     // defines whether to save IndexNoFilters index.
+	CompositeIdxLabels []string      // label list which defines composite indexes to improve the search performance
+	IgnoreCase         bool          // defines whether to ignore case on search
+	CaseFolding        bool          // defines whether to ignore case with Unicode case folding instead of IgnoreCase
+	CaseLocale         string        // defines the locale for CaseFolding such as "tr" or "az"
+	Normalization      Normalization // defines Unicode normalization applied before tokenizing
+	FoldAccents        bool          // defines whether to search without distinction of accents
+	FoldKana           bool          // defines whether to search hiragana and katakana without distinction
+	Graphemes          bool          // defines whether to tokenize by grapheme clusters instead of runes
+	WordBoundary       *WordBoundary // defines characters which split words, or nil to split at spaces only
+	SaveNoFiltersIndex bool          // defines whether to save IndexNoFilters index.
+
+	AffixBounds  map[string]LengthBounds    // defines length bounds of prefix and suffix indexes per label
+	PartialGrams map[string]int             // defines the gram length of partial match indexes per label, 2 by default
+	IPPrefixes   map[string]IPPrefixLengths // defines prefix lengths of IP address indexes per label
 }
 
 // DefaultConfig - default configuration.
@@ -33,9 +46,43 @@ func ValidateConfig(conf *Config) (*Config, error) {
 	if len(conf.CompositeIdxLabels) > MaxCompositeIndexLabels {
 		return nil, xerrors.Errorf("CompositeIdxLabels size exceeds %d", MaxCompositeIndexLabels)
 	}
+	if conf.CaseLocale != "" {
+		if _, err := language.Parse(conf.CaseLocale); err != nil {
+			return nil, xerrors.Errorf("invalid CaseLocale %q: %w", conf.CaseLocale, err)
+		}
+	}
+	for label, bounds := range conf.AffixBounds {
+		if !bounds.valid() {
+			return nil, xerrors.Errorf("invalid AffixBounds of %q: %+v", label, bounds)
+		}
+	}
+	for label, n := range conf.PartialGrams {
+		if n < 1 {
+			return nil, xerrors.Errorf("invalid PartialGrams of %q: %d", label, n)
+		}
+	}
+	for label, prefixes := range conf.IPPrefixes {
+		if !prefixes.valid() {
+			return nil, xerrors.Errorf("invalid IPPrefixes of %q: %+v", label, prefixes)
+		}
+	}
+	if !conf.Normalization.valid() {
+		return nil, xerrors.Errorf("unknown Normalization: %d", conf.Normalization)
+	}
 	return conf, nil
 }
 
+// defaultPartialGrams - gram length of partial match indexes for labels without Config.PartialGrams.
+const defaultPartialGrams = 2
+
+// partialGrams - returns the gram length of partial match indexes of the label.
+func (conf *Config) partialGrams(label string) int {
+	if n := conf.PartialGrams[label]; n > 0 {
+		return n
+	}
+	return defaultPartialGrams
+}
+
 // MustValidateConfig - validates fields and panics if it's invalid.
 func MustValidateConfig(conf *Config) *Config {
 	conf, err := ValidateConfig(conf)
@@ -49,6 +96,18 @@ func MustValidateConfig(conf *Config) *Config {
 // key=label, value=index set
 type indexesMap map[string]map[string]struct{}
 
+// clone - returns a deep copy of m.
+func (m indexesMap) clone() indexesMap {
+	cloned := make(indexesMap, len(m))
+	for label, tokens := range m {
+		cloned[label] = make(map[string]struct{}, len(tokens))
+		for t := range tokens {
+			cloned[label][t] = struct{}{}
+		}
+	}
+	return cloned
+}
+
 // buildIndexes - builds indexes from m.
 // m is map[label]tokens.
 func buildIndexes(m indexesMap, labelsToExclude []string) map[string]bool {
@@ -166,28 +225,34 @@ func addSomething(v interface{}, label string, indexes interface{}) {
 	if !ok {
 		return
 	}
+	x.add(label, somethingTokens(indexes)...)
+}
 
+// somethingTokens - converts a slice or a string convertible value into tokens.
+func somethingTokens(indexes interface{}) []string {
 	rv := reflect.Indirect(reflect.ValueOf(indexes))
 
 	switch rv.Kind() {
 	case reflect.Array, reflect.Slice:
+		tokens := make([]string, 0, rv.Len())
 		for i := 0; i < rv.Len(); i++ {
 			index := rv.Index(i)
 			if !index.CanInterface() {
 				continue
 			}
-			x.add(label, fmt.Sprintf("%v", index.Interface()))
+			tokens = append(tokens, fmt.Sprintf("%v", index.Interface()))
 		}
+		return tokens
 	case reflect.Struct:
 		if rv.Type() == timeType {
 			unix := rv.Interface().(time.Time).UnixNano()
-			x.add(label, strconv.FormatInt(unix, 10))
-			break
+			return []string{strconv.FormatInt(unix, 10)}
 		}
 		fallthrough
 	default:
 		if rv.CanInterface() {
-			x.add(label, fmt.Sprintf("%v", rv.Interface()))
+			return []string{fmt.Sprintf("%v", rv.Interface())}
 		}
 	}
+	return nil
 }
@@ -1,7 +1,11 @@
 package xim
 
 import (
+	"net"
+	"reflect"
+	"regexp"
 	"testing"
+	"time"
 )
 
 func TestValidateConfig(t *testing.T) {
@@ -27,6 +31,41 @@ func TestValidateConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("Unknown Normalization", func(tr *testing.T) {
+		conf := &Config{Normalization: NormalizationNFKC + 1}
+		if _, err := ValidateConfig(conf); err == nil {
+			tr.Error("unknown Normalization expected: err != nil, but was: err = nil\n")
+		}
+	})
+
+	t.Run("Invalid CaseLocale", func(tr *testing.T) {
+		conf := &Config{CaseFolding: true, CaseLocale: "!!"}
+		if _, err := ValidateConfig(conf); err == nil {
+			tr.Error("invalid CaseLocale expected: err != nil, but was: err = nil\n")
+		}
+	})
+
+	t.Run("Invalid PartialGrams", func(tr *testing.T) {
+		conf := &Config{PartialGrams: map[string]int{"a": 0}}
+		if _, err := ValidateConfig(conf); err == nil {
+			tr.Error("invalid PartialGrams expected: err != nil, but was: err = nil\n")
+		}
+	})
+
+	t.Run("Invalid IPPrefixes", func(tr *testing.T) {
+		conf := &Config{IPPrefixes: map[string]IPPrefixLengths{"a": {IPv4: []int{8, 33}}}}
+		if _, err := ValidateConfig(conf); err == nil {
+			tr.Error("invalid IPPrefixes expected: err != nil, but was: err = nil\n")
+		}
+	})
+
+	t.Run("Invalid AffixBounds", func(tr *testing.T) {
+		conf := &Config{AffixBounds: map[string]LengthBounds{"a": {Min: 3, Max: 2}}}
+		if _, err := ValidateConfig(conf); err == nil {
+			tr.Error("invalid AffixBounds expected: err != nil, but was: err = nil\n")
+		}
+	})
+
 	t.Run("ValidateConfig(DefaultConfig)", func(tr *testing.T) {
 		if _, err := ValidateConfig(DefaultConfig); err != nil {
 			tr.Errorf("expected: error = nil, but was: error = [%v]\n", err)
@@ -115,6 +154,583 @@ func TestAddBiunigramsIndexAndFilter(t *testing.T) {
 	}
 }
 
+func TestAddNgramsIndexAndFilter(t *testing.T) {
+	idx := NewIndexes(nil)
+	idx.AddNgrams("label1", "abc dあいbCh", 3)
+	builtIndexes := idx.MustBuild()
+
+	for _, query := range []string{"dあいb", "あい", "C"} {
+		filter := NewFilters(nil)
+		filter.AddNgrams("label1", query, 3) // mid match of idx
+		builtFilters := filter.MustBuild()
+
+		// All the contents of filter are present in index
+		for builtFilter := range builtFilters {
+			if !contains(t, builtIndexes, builtFilter) {
+				t.Errorf("filter: %s not contains", builtFilter)
+			}
+		}
+	}
+}
+
+func TestNormalizationIndexAndFilter(t *testing.T) {
+	conf := &Config{Normalization: NormalizationNFKC, IgnoreCase: true}
+
+	idx := NewIndexes(conf)
+	idx.AddBiunigrams("label1", "ﾃﾞｰﾀベース Ｓｅｒｖｅｒ")
+	builtIndexes := idx.MustBuild()
+
+	for _, query := range []string{"データ", "ﾍﾞｰｽ", "server", "ＳＥＲ"} {
+		filter := NewFilters(conf)
+		filter.AddBiunigrams("label1", query)
+		builtFilters := filter.MustBuild()
+
+		for builtFilter := range builtFilters {
+			if !contains(t, builtIndexes, builtFilter) {
+				t.Errorf("%s: filter: %s not contains", query, builtFilter)
+			}
+		}
+	}
+}
+
+func TestFoldKanaIndexAndFilter(t *testing.T) {
+	conf := &Config{FoldKana: true}
+
+	idx := NewIndexes(conf)
+	idx.AddBiunigrams("label1", "リンゴジュース")
+	idx.AddPrefixes("label2", "リンゴジュース")
+	idx.AddSuffixes("label3", "リンゴジュース")
+	builtIndexes := idx.MustBuild()
+
+	filter := NewFilters(conf)
+	filter.AddBiunigrams("label1", "んご")
+	filter.AddPrefix("label2", "りんご")
+	filter.AddSuffix("label3", "じゅーす")
+	builtFilters := filter.MustBuild()
+
+	for builtFilter := range builtFilters {
+		if !contains(t, builtIndexes, builtFilter) {
+			t.Errorf("filter: %s not contains", builtFilter)
+		}
+	}
+}
+
+func TestFoldAccentsIndexAndFilter(t *testing.T) {
+	conf := &Config{FoldAccents: true, IgnoreCase: true}
+
+	idx := NewIndexes(conf)
+	idx.AddBiunigrams("label1", "Café Ærø")
+	idx.AddPrefixes("label2", "Café Ærø")
+	builtIndexes := idx.MustBuild()
+
+	filter := NewFilters(conf)
+	filter.AddBiunigrams("label1", "cafe")
+	filter.AddPrefix("label2", "aero")
+	builtFilters := filter.MustBuild()
+
+	for builtFilter := range builtFilters {
+		if !contains(t, builtIndexes, builtFilter) {
+			t.Errorf("filter: %s not contains", builtFilter)
+		}
+	}
+}
+
+func TestCaseFoldingIndexAndFilter(t *testing.T) {
+	conf := &Config{CaseFolding: true, CaseLocale: "tr"}
+
+	idx := NewIndexes(conf)
+	idx.AddBiunigrams("label1", "İSTANBUL Straße")
+	builtIndexes := idx.MustBuild()
+
+	filter := NewFilters(conf)
+	filter.AddBiunigrams("label1", "istanbul strasse")
+	builtFilters := filter.MustBuild()
+
+	for builtFilter := range builtFilters {
+		if !contains(t, builtIndexes, builtFilter) {
+			t.Errorf("filter: %s not contains", builtFilter)
+		}
+	}
+}
+
+func TestAffixBoundsIndexAndFilter(t *testing.T) {
+	conf := &Config{AffixBounds: map[string]LengthBounds{"label1": {Min: 2, Max: 4}}}
+
+	idx := NewIndexes(conf)
+	idx.AddPrefixes("label1", "abcdefgh ijklmnop")
+	builtIndexes := idx.MustBuild()
+	if len(builtIndexes) != 6 {
+		t.Errorf("len(builtIndexes) expected: %d, but was: %d", 6, len(builtIndexes))
+	}
+
+	for _, query := range []string{"ab", "abcd", "ijklmn"} {
+		filter := NewFilters(conf)
+		filter.AddPrefix("label1", query)
+		builtFilters := filter.MustBuild()
+
+		for builtFilter := range builtFilters {
+			if !contains(t, builtIndexes, builtFilter) {
+				t.Errorf("filter: %s not contains", builtFilter)
+			}
+		}
+	}
+}
+
+func TestPhrasePrefixesIndexAndFilter(t *testing.T) {
+	conf := &Config{IgnoreCase: true, AffixBounds: map[string]LengthBounds{"label2": {Max: 10}}}
+
+	idx := NewIndexes(conf)
+	idx.AddPhrasePrefixes("label1", "Harry Potter and the Philosopher's Stone")
+	idx.AddPhrasePrefixes("label2", "Harry Potter and the Philosopher's Stone")
+	idx.AddPhraseSuffixes("label3", "Harry Potter and the Philosopher's Stone")
+	builtIndexes := idx.MustBuild()
+
+	filter := NewFilters(conf)
+	filter.AddPhrasePrefix("label1", "harry  pot")
+	filter.AddPhrasePrefix("label2", "harry potter and")
+	filter.AddPhraseSuffix("label3", "the philosopher's stone")
+	builtFilters := filter.MustBuild()
+
+	for builtFilter := range builtFilters {
+		if !contains(t, builtIndexes, builtFilter) {
+			t.Errorf("filter: %s not contains", builtFilter)
+		}
+	}
+
+	expected := []PostFilter{{Label: "label2", Match: MatchPhrasePrefix, Value: "harry potter and"}}
+	if postFilters := filter.PostFilters(); !reflect.DeepEqual(postFilters, expected) {
+		t.Errorf("unexpected, actual: `%v`, expected: `%v`", postFilters, expected)
+	}
+
+	// word order is kept
+	filter = NewFilters(conf)
+	filter.AddPhrasePrefix("label1", "potter harry")
+	for builtFilter := range filter.MustBuild() {
+		if contains(t, builtIndexes, builtFilter) {
+			t.Errorf("filter: %s contains", builtFilter)
+		}
+	}
+}
+
+func TestAddShinglesIndexAndFilter(t *testing.T) {
+	idx := NewIndexes(nil)
+	idx.AddShingles("label1", "pizza new york", 2)
+	builtIndexes := idx.MustBuild()
+
+	filter := NewFilters(nil)
+	filter.AddPhrase("label1", "new york", 2)
+	for builtFilter := range filter.MustBuild() {
+		if !contains(t, builtIndexes, builtFilter) {
+			t.Errorf("filter: %s not contains", builtFilter)
+		}
+	}
+
+	// word order is kept
+	filter = NewFilters(nil)
+	filter.AddPhrase("label1", "new york pizza", 2)
+	found := true
+	for builtFilter := range filter.MustBuild() {
+		found = found && contains(t, builtIndexes, builtFilter)
+	}
+	if found {
+		t.Error("filters of `new york pizza` are contained in `pizza new york`")
+	}
+}
+
+func TestAddStemsIndexAndFilter(t *testing.T) {
+	conf := &Config{IgnoreCase: true}
+
+	idx := NewIndexes(conf)
+	idx.AddStems("label1", "Run shoe for the trail")
+	builtIndexes := idx.MustBuild()
+
+	filter := NewFilters(conf)
+	filter.AddStems("label1", "Running Shoes")
+	builtFilters := filter.MustBuild()
+
+	if len(builtFilters) != 2 {
+		t.Errorf("len(builtFilters) expected: %d, but was: %d", 2, len(builtFilters))
+	}
+	for builtFilter := range builtFilters {
+		if !contains(t, builtIndexes, builtFilter) {
+			t.Errorf("filter: %s not contains", builtFilter)
+		}
+	}
+}
+
+func TestAddPhoneticIndexAndFilter(t *testing.T) {
+	conf := &Config{IgnoreCase: true}
+
+	idx := NewIndexes(conf)
+	idx.AddPhonetic("label1", "Katherine Smyth", PhoneticMetaphone)
+	builtIndexes := idx.MustBuild()
+
+	filter := NewFilters(conf)
+	filter.AddPhonetic("label1", "catherine smith", PhoneticMetaphone)
+	builtFilters := filter.MustBuild()
+
+	if len(builtFilters) != 2 {
+		t.Errorf("len(builtFilters) expected: %d, but was: %d", 2, len(builtFilters))
+	}
+	for builtFilter := range builtFilters {
+		if !contains(t, builtIndexes, builtFilter) {
+			t.Errorf("filter: %s not contains", builtFilter)
+		}
+	}
+}
+
+func TestAddDoubleMetaphoneIndexAndFilter(t *testing.T) {
+	builtIndexes := NewIndexes(nil).AddPhonetic("label1", "Catherine Schmidt", PhoneticDoubleMetaphone).MustBuild()
+
+	for _, q := range []string{"katherine smith", "Schmidt", "Smyth Kathryn"} {
+		filter := NewFilters(nil).AddPhonetic("label1", q, PhoneticDoubleMetaphone)
+		found := false
+		for _, builtFilters := range filter.MustBuildAll() {
+			all := true
+			for builtFilter := range builtFilters {
+				all = all && contains(t, builtIndexes, builtFilter)
+			}
+			found = found || all
+		}
+		if !found {
+			t.Errorf("%s: expected: match", q)
+		}
+	}
+
+	// words beyond MaxFilterAlternatives are searched with primary codes only
+	filter := NewFilters(nil).AddPhonetic("label1", "smith smith2 smith3 smith4 smith5 smith6", PhoneticDoubleMetaphone)
+	if _, err := filter.BuildAll(); err != nil {
+		t.Errorf("error = %s, wants = nil", err)
+	}
+}
+
+func TestAddFuzzyIndexAndFilter(t *testing.T) {
+	conf := &Config{IgnoreCase: true}
+
+	idx := NewIndexes(conf)
+	idx.AddDeletions("label1", "Johnson", 1)
+	builtIndexes := idx.MustBuild()
+
+	for _, query := range []string{"johnson", "jonson", "johnsson", "jihnson"} {
+		filter := NewFilters(conf)
+		filter.AddFuzzy("label1", query, 1)
+
+		matched := false
+		for _, builtFilters := range filter.MustBuildAll() {
+			all := true
+			for builtFilter := range builtFilters {
+				all = all && builtIndexes[builtFilter]
+			}
+			matched = matched || all
+		}
+		if !matched {
+			t.Errorf("%s: expected: matched, but was: not matched", query)
+		}
+	}
+
+	filter := NewFilters(conf)
+	filter.AddFuzzy("label1", "jackson", 1)
+	for _, builtFilters := range filter.MustBuildAll() {
+		for builtFilter := range builtFilters {
+			if builtIndexes[builtFilter] {
+				t.Errorf("filter: %s unexpectedly matched", builtFilter)
+			}
+		}
+	}
+	t.Run("multiple words", func(t *testing.T) {
+		builtIndexes := NewIndexes(conf).AddDeletions("label1", "Robert Brown", 1).MustBuild()
+		for _, query := range []string{"robert brown", "robrt browne", "Rob3rt Brwn"} {
+			filter := NewFilters(conf).AddFuzzy("label1", query, 1)
+
+			matched := false
+			for _, builtFilters := range filter.MustBuildAll() {
+				all := true
+				for builtFilter := range builtFilters {
+					all = all && builtIndexes[builtFilter]
+				}
+				matched = matched || all
+			}
+			if !matched {
+				t.Errorf("%s: expected: matched, but was: not matched", query)
+			}
+			if !filter.Matcher().Match(map[string]interface{}{"label1": "Robert Brown"}) {
+				t.Errorf("%s: matcher expected: matched, but was: not matched", query)
+			}
+		}
+	})
+}
+
+func TestAddWildcardIndexAndFilter(t *testing.T) {
+	conf := &Config{IgnoreCase: true}
+	labels := WildcardLabels{Prefix: "p", Suffix: "s", Partial: "b"}
+	value := "Harry Potter"
+
+	idx := NewIndexes(conf)
+	idx.AddPrefixes(labels.Prefix, value)
+	idx.AddSuffixes(labels.Suffix, value)
+	idx.AddBiunigrams(labels.Partial, value)
+	builtIndexes := idx.MustBuild()
+
+	for _, pattern := range []string{"ha?ry", "pot*", "*ter", "h*y", "*tt*"} {
+		filter := NewFilters(conf)
+		filter.AddWildcard(labels, pattern)
+
+		for builtFilter := range filter.MustBuild() {
+			if !contains(t, builtIndexes, builtFilter) {
+				t.Errorf("%s: filter: %s not contains", pattern, builtFilter)
+			}
+		}
+		if !WildcardMatch(conf, pattern, value) {
+			t.Errorf("%s: expected: matched, but was: not matched", pattern)
+		}
+	}
+
+	// candidates matched by the filters are dropped by WildcardMatch
+	filter := NewFilters(conf)
+	filter.AddWildcard(labels, "h*r")
+	for builtFilter := range filter.MustBuild() {
+		if !contains(t, builtIndexes, builtFilter) {
+			t.Errorf("filter: %s not contains", builtFilter)
+		}
+	}
+	if WildcardMatch(conf, "h*r", value) {
+		t.Errorf("expected: not matched, but was: matched")
+	}
+}
+
+func TestAddRegexpIndexAndFilter(t *testing.T) {
+	values := []string{
+		"GET /users 200 12ms",
+		"POST /users 500 timeout",
+		"GET /items 404 3ms",
+	}
+	re := regexp.MustCompile(`(POST|GET) /users [45]\d\d`)
+
+	for i, value := range values {
+		idx := NewIndexes(nil)
+		idx.AddBigrams("label1", value)
+		builtIndexes := idx.MustBuild()
+
+		filter := NewFilters(nil)
+		filter.AddRegexp("label1", re)
+
+		matched := false
+		for _, builtFilters := range filter.MustBuildAll() {
+			all := true
+			for builtFilter := range builtFilters {
+				all = all && builtIndexes[builtFilter]
+			}
+			matched = matched || all
+		}
+		// candidates contain all the matches
+		if re.MatchString(value) && !matched {
+			t.Errorf("%d: expected: matched, but was: not matched", i)
+		}
+		// the filters drop values without the literals
+		if i == 2 && matched {
+			t.Errorf("%d: expected: not matched, but was: matched", i)
+		}
+	}
+}
+
+func TestAddPartialIndexAndFilter(t *testing.T) {
+	conf := &Config{IgnoreCase: true, PartialGrams: map[string]int{"label2": 3}}
+
+	idx := NewIndexes(conf)
+	idx.AddPartial("label1", "Harry Potter")
+	idx.AddPartial("label2", "Harry Potter")
+	builtIndexes := idx.MustBuild()
+
+	for _, label := range []string{"label1", "label2"} {
+		for _, query := range []string{"h", "ot", "tte", "arry", "rry pot"} {
+			filter := NewFilters(conf)
+			filter.AddPartial(label, query)
+
+			for builtFilter := range filter.MustBuild() {
+				if !contains(t, builtIndexes, builtFilter) {
+					t.Errorf("%s: filter: %s not contains", query, builtFilter)
+				}
+			}
+		}
+	}
+}
+
+func TestAddRangeIndexAndFilter(t *testing.T) {
+	for _, spec := range []NumberRangeSpec{
+		{Min: -50, Max: 150},
+		{Min: -50, Max: 150, Base: 10},
+	} {
+		builtIndexes := make([]map[string]bool, 0, 200)
+		for v := spec.Min; v < spec.Max; v++ {
+			builtIndexes = append(builtIndexes, NewIndexes(nil).AddNumberRange("label1", v, spec).MustBuild())
+		}
+
+		for _, r := range [][2]int64{{-50, 150}, {0, 100}, {3, 97}, {-13, 42}, {77, 78}, {120, 150}} {
+			filter := NewFilters(nil).AddRange("label1", r[0], r[1], spec)
+			builtList := filter.MustBuildAll()
+
+			for i, indexes := range builtIndexes {
+				v := spec.Min + int64(i)
+				matched := false
+				for _, builtFilters := range builtList {
+					all := true
+					for builtFilter := range builtFilters {
+						all = all && indexes[builtFilter]
+					}
+					matched = matched || all
+				}
+				if expected := r[0] <= v && v < r[1]; matched != expected {
+					t.Errorf("%+v, %v, %d: expected: %v, but was: %v", spec, r, v, expected, matched)
+				}
+			}
+			if filter.MayContainFalsePositives() {
+				t.Errorf("%+v, %v: expected: no false positives, but was: %v", spec, r, filter.PostFilters())
+			}
+		}
+	}
+}
+
+func TestAddTimeRangeIndexAndFilter(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	spec := TimeBucketSpec{Granularity: TimeHour, Location: jst}
+	base := time.Date(2020, 1, 30, 0, 0, 0, 0, jst)
+
+	builtIndexes := make([]map[string]bool, 0, 24*5)
+	for h := 0; h < 24*5; h++ {
+		at := base.Add(time.Duration(h) * time.Hour)
+		builtIndexes = append(builtIndexes, NewIndexes(nil).AddTimeBuckets("label1", at, spec).MustBuild())
+	}
+
+	for _, r := range [][2]int{{0, 24}, {22, 50}, {48, 72}, {47, 97}, {5, 6}} {
+		start, end := base.Add(time.Duration(r[0])*time.Hour), base.Add(time.Duration(r[1])*time.Hour)
+		filter := NewFilters(nil).AddTimeRange("label1", start, end, spec)
+		builtList := filter.MustBuildAll()
+
+		for h, indexes := range builtIndexes {
+			matched := false
+			for _, builtFilters := range builtList {
+				all := true
+				for builtFilter := range builtFilters {
+					all = all && indexes[builtFilter]
+				}
+				matched = matched || all
+			}
+			if expected := r[0] <= h && h < r[1]; matched != expected {
+				t.Errorf("%v, %d: expected: %v, but was: %v", r, h, expected, matched)
+			}
+		}
+		if filter.MayContainFalsePositives() {
+			t.Errorf("%v: expected: no false positives, but was: %v", r, filter.PostFilters())
+		}
+	}
+}
+
+func TestAddOverlapIndexAndFilter(t *testing.T) {
+	spec := TimeBucketSpec{Granularity: TimeHour}
+	base := time.Date(2020, 1, 30, 0, 0, 0, 0, time.UTC)
+	hour := func(h int) time.Time {
+		return base.Add(time.Duration(h) * time.Hour)
+	}
+
+	intervals := [][2]int{{0, 1}, {0, 24}, {5, 50}, {23, 25}, {30, 31}, {47, 120}, {0, 24 * 40}}
+	builtIndexes := make([]map[string]bool, 0, len(intervals))
+	for _, i := range intervals {
+		builtIndexes = append(builtIndexes, NewIndexes(nil).AddInterval("label1", hour(i[0]), hour(i[1]), spec).MustBuild())
+	}
+
+	matches := func(filter *Filters, indexes map[string]bool) bool {
+		for _, builtFilters := range filter.MustBuildAll() {
+			all := true
+			for builtFilter := range builtFilters {
+				all = all && indexes[builtFilter]
+			}
+			if all {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, q := range [][2]int{{0, 1}, {1, 5}, {24, 30}, {26, 47}, {49, 50}, {100, 200}, {24 * 40, 24 * 41}} {
+		filter := NewFilters(nil).AddOverlap("label1", hour(q[0]), hour(q[1]), spec)
+		for i, interval := range intervals {
+			// intervals and queries aligned to the buckets have no false positives
+			expected := interval[0] < q[1] && q[0] < interval[1]
+			if actual := matches(filter, builtIndexes[i]); actual != expected {
+				t.Errorf("%v, %v: expected: %v, but was: %v", q, interval, expected, actual)
+			}
+		}
+	}
+
+	for _, h := range []int{0, 23, 24, 49, 119, 24 * 40} {
+		filter := NewFilters(nil).AddActiveAt("label1", hour(h).Add(time.Minute), spec)
+		for i, interval := range intervals {
+			expected := interval[0] <= h && h < interval[1]
+			if actual := matches(filter, builtIndexes[i]); actual != expected {
+				t.Errorf("%d, %v: expected: %v, but was: %v", h, interval, expected, actual)
+			}
+		}
+	}
+}
+
+func TestAddOverlapLongIndexAndFilter(t *testing.T) {
+	spec := TimeBucketSpec{Granularity: TimeDate}
+	year := func(y int) time.Time {
+		return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	intervals := [][2]time.Time{
+		{year(1500), year(2100)},
+		{year(2020), time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{year(2020), year(2020).AddDate(0, 1, 0)},
+	}
+	builtIndexes := make([]map[string]bool, 0, len(intervals))
+	for _, i := range intervals {
+		built, err := NewIndexes(nil).AddInterval("label1", i[0], i[1], spec).Build()
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", i, err)
+		}
+		builtIndexes = append(builtIndexes, built)
+	}
+
+	for _, q := range [][2]time.Time{
+		{year(2020).AddDate(0, 0, 10), year(2020).AddDate(0, 0, 11)},
+		{year(2030), year(2031)},
+		{year(1990), year(1991)},
+		// more buckets than MaxFilterAlternatives are searched only by the PostFilter
+		{year(2000), year(2040)},
+	} {
+		filter := NewFilters(nil).AddOverlap("label1", q[0], q[1], spec)
+		builtFilters, err := filter.BuildAll()
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", q, err)
+		}
+
+		matcher := filter.Matcher()
+		for i, interval := range intervals {
+			expected := interval[0].Before(q[1]) && q[0].Before(interval[1])
+			if actual := matcher.Match(map[string]interface{}{"label1": interval}); actual != expected {
+				t.Errorf("%v, %v: matcher expected: %v, but was: %v", q, interval, expected, actual)
+			}
+			if !expected {
+				continue
+			}
+
+			matched := false
+			for _, f := range builtFilters {
+				all := true
+				for builtFilter := range f {
+					all = all && builtIndexes[i][builtFilter]
+				}
+				matched = matched || all
+			}
+			if !matched {
+				t.Errorf("%v, %v: expected: matched, but was: not matched", q, interval)
+			}
+		}
+	}
+}
+
 func TestInFilterIndexAndFilter(t *testing.T) {
 	inBuilder := NewInBuilder()
 	status1 := inBuilder.NewBit()
@@ -146,3 +762,195 @@ func contains(t *testing.T, m map[string]bool, target string) bool {
 	}
 	return false
 }
+
+func TestAddGeoIndexAndFilter(t *testing.T) {
+	const precision = 8
+	shops := [][2]float64{
+		{35.681236, 139.767125}, // Tokyo station
+		{35.689592, 139.700413}, // Shinjuku station
+		{35.658034, 139.701636}, // Shibuya station
+		{34.702485, 135.495951}, // Osaka station
+		{35.685175, 139.752799}, // Imperial Palace
+	}
+	builtIndexes := make([]map[string]bool, 0, len(shops))
+	for _, s := range shops {
+		builtIndexes = append(builtIndexes, NewIndexes(nil).AddGeo("label1", s[0], s[1], precision).MustBuild())
+	}
+
+	matches := func(filter *Filters, indexes map[string]bool) bool {
+		for _, builtFilters := range filter.MustBuildAll() {
+			all := true
+			for builtFilter := range builtFilters {
+				all = all && indexes[builtFilter]
+			}
+			if all {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, radius := range []float64{100, 1500, 7000, 20000, 500000} {
+		filter := NewFilters(nil).AddGeoWithin("label1", 35.681236, 139.767125, radius, precision)
+		matcher := filter.Matcher()
+		for i, s := range shops {
+			expected := GeoDistance(35.681236, 139.767125, s[0], s[1]) <= radius
+			// cells may contain false positives, but never miss
+			if expected && !matches(filter, builtIndexes[i]) {
+				t.Errorf("%f, %v: expected: match", radius, s)
+			}
+			if actual := matcher.Match(map[string]interface{}{"label1": s}); actual != expected {
+				t.Errorf("%f, %v: expected: %v, but was: %v", radius, s, expected, actual)
+			}
+		}
+	}
+
+	filter := NewFilters(nil).AddGeoBox("label1", 35.65, 139.69, 35.69, 139.71, precision)
+	matcher := filter.Matcher()
+	for i, s := range shops {
+		expected := i == 1 || i == 2
+		if expected && !matches(filter, builtIndexes[i]) {
+			t.Errorf("%v: expected: match", s)
+		}
+		if actual := matcher.Match(map[string]interface{}{"label1": s}); actual != expected {
+			t.Errorf("%v: expected: %v, but was: %v", s, expected, actual)
+		}
+	}
+	if !filter.MayContainFalsePositives() {
+		t.Errorf("expected: false positives")
+	}
+}
+
+func TestAddCIDRIndexAndFilter(t *testing.T) {
+	prefixes := IPPrefixLengths{IPv4: []int{8, 16, 24, 32}, IPv6: []int{32, 64, 128}}
+	conf := &Config{IPPrefixes: map[string]IPPrefixLengths{"label1": prefixes}}
+	ips := []string{"10.2.0.1", "10.2.255.254", "10.3.0.1", "192.168.1.10", "2001:db8::1", "2001:db8:1::1", "2001:db9::1"}
+	builtIndexes := make([]map[string]bool, 0, len(ips))
+	for _, ip := range ips {
+		builtIndexes = append(builtIndexes, NewIndexes(conf).AddIP("label1", net.ParseIP(ip)).MustBuild())
+	}
+
+	matches := func(filter *Filters, indexes map[string]bool) bool {
+		for _, builtFilters := range filter.MustBuildAll() {
+			all := true
+			for builtFilter := range builtFilters {
+				all = all && indexes[builtFilter]
+			}
+			if all {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, c := range []struct {
+		cidr  string
+		exact bool
+	}{
+		{"10.2.0.0/16", true},
+		{"10.2.0.0/15", true},
+		{"10.2.128.0/17", false},
+		{"0.0.0.0/0", true},
+		{"192.168.1.10/32", true},
+		{"2001:db8::/32", true},
+		{"2001:db8::/48", false},
+		{"2001:db8::/31", true},
+		{"::/0", true},
+	} {
+		_, cidr, err := net.ParseCIDR(c.cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filter := NewFilters(conf).AddCIDR("label1", cidr)
+		if actual := filter.MayContainFalsePositives(); actual == c.exact {
+			t.Errorf("%s: false positives expected: %v, but was: %v", c.cidr, !c.exact, actual)
+		}
+
+		matcher := filter.Matcher()
+		for i, ip := range ips {
+			expected := cidr.Contains(net.ParseIP(ip))
+			actual := matches(filter, builtIndexes[i])
+			if expected && !actual || c.exact && actual != expected {
+				t.Errorf("%s, %s: expected: %v, but was: %v", c.cidr, ip, expected, actual)
+			}
+			if actual := matcher.Match(map[string]interface{}{"label1": ip}); actual != expected {
+				t.Errorf("%s, %s: matcher expected: %v, but was: %v", c.cidr, ip, expected, actual)
+			}
+		}
+	}
+}
+
+func TestWideInFilterIndexAndFilter(t *testing.T) {
+	inBuilder := NewInBuilder()
+	countries := make([]Bit, 0, MaxInBits)
+	for i := 0; i < MaxInBits; i++ {
+		countries = append(countries, inBuilder.NewBit())
+	}
+
+	builtIndexes := make([]map[string]bool, 0, len(countries))
+	for _, c := range countries {
+		builtIndexes = append(builtIndexes, NewIndexes(nil).Add("label1", inBuilder.Indexes(c)...).MustBuild())
+	}
+
+	query := []Bit{countries[0], countries[7], countries[8], countries[50], countries[63]}
+	filter := NewFilters(nil).AddIn("label1", inBuilder, query...)
+	builtFilters := filter.MustBuildAll()
+	if len(builtFilters) != 5 {
+		t.Errorf("filters expected: %d, but was: %d", 5, len(builtFilters))
+	}
+
+	matcher := filter.Matcher()
+	for i, c := range countries {
+		expected := false
+		for _, q := range query {
+			expected = expected || q == c
+		}
+
+		actual := false
+		for _, f := range builtFilters {
+			all := true
+			for builtFilter := range f {
+				all = all && builtIndexes[i][builtFilter]
+			}
+			actual = actual || all
+		}
+		if actual != expected {
+			t.Errorf("%d: expected: %v, but was: %v", i, expected, actual)
+		}
+		if actual := matcher.Match(map[string]interface{}{"label1": c}); actual != expected {
+			t.Errorf("%d: matcher expected: %v, but was: %v", i, expected, actual)
+		}
+	}
+}
+
+func TestWideInFilterIndexOfMultipleGroups(t *testing.T) {
+	inBuilder := NewInBuilder()
+	countries := make([]Bit, 0, MaxInBits)
+	for i := 0; i < MaxInBits; i++ {
+		countries = append(countries, inBuilder.NewBit())
+	}
+
+	for _, values := range [][]Bit{
+		{countries[0], countries[4], countries[8], countries[12], countries[16]},
+		countries,
+	} {
+		builtIndexes, err := NewIndexes(nil).Add("label1", inBuilder.Indexes(values...)...).Build()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, c := range values {
+			matched := false
+			for _, f := range NewFilters(nil).AddIn("label1", inBuilder, c).MustBuildAll() {
+				all := true
+				for builtFilter := range f {
+					all = all && builtIndexes[builtFilter]
+				}
+				matched = matched || all
+			}
+			if !matched {
+				t.Errorf("%d values: %x expected to match", len(values), c)
+			}
+		}
+	}
+}